@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/status"
+)
+
+// histogramBuckets — верхние границы корзин гистограммы задержек
+var histogramBuckets = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+}
+
+// histogramWidth — ширина самой длинной полосы гистограммы в символах
+const histogramWidth = 40
+
+// benchResult — итог нагрузочного прогона
+type benchResult struct {
+	Method      string         `json:"method"`
+	Requests    int            `json:"requests"`
+	Concurrency int            `json:"concurrency"`
+	Duration    string         `json:"duration"`
+	RPS         float64        `json:"rps"`
+	Codes       map[string]int `json:"codes"`
+	Latency     latencyStats   `json:"latency"`
+	Histogram   []bucket       `json:"histogram"`
+}
+
+type latencyStats struct {
+	Min string `json:"min"`
+	Avg string `json:"avg"`
+	P50 string `json:"p50"`
+	P90 string `json:"p90"`
+	P95 string `json:"p95"`
+	P99 string `json:"p99"`
+	Max string `json:"max"`
+}
+
+type bucket struct {
+	// LE — верхняя граница корзины; "+Inf" для последней
+	LE    string `json:"le"`
+	Count int    `json:"count"`
+}
+
+// runBench многократно вызывает унарный метод и выводит статистику задержек
+func runBench(args []string) error {
+	fs, f := newFlagSet("bench", "<метод>")
+	data := fs.String("d", "{}", "тело запроса в JSON; @файл — из файла, @- — из stdin")
+	total := fs.Int("n", 200, "число вызовов")
+	concurrency := fs.Int("c", 10, "число одновременных вызовов")
+	duration := fs.Duration("duration", 0, "длительность прогона; если задана, -n игнорируется")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("нужно указать метод")
+	}
+	if *concurrency <= 0 {
+		return errors.New("-c должен быть больше нуля")
+	}
+
+	body, err := readBody(*data)
+	if err != nil {
+		return err
+	}
+
+	client, dyn, err := f.newDynamicClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	method := fs.Arg(0)
+	// Описание метода загружается до старта, чтобы не учитывать reflection в задержках
+	if _, err := dyn.FindMethod(ctx, method); err != nil {
+		return err
+	}
+
+	runCtx := ctx
+	if *duration > 0 {
+		var stop context.CancelFunc
+		runCtx, stop = context.WithTimeout(ctx, *duration)
+		defer stop()
+	}
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		codes     = make(map[string]int)
+		next      int
+	)
+	// take выдает номер очередного вызова; false — прогон завершен
+	take := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if runCtx.Err() != nil || (*duration <= 0 && next >= *total) {
+			return false
+		}
+		next++
+		return true
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for take() {
+				callStart := time.Now()
+				_, err := dyn.InvokeJSON(runCtx, method, body)
+				elapsed := time.Since(callStart)
+				if err != nil && runCtx.Err() != nil {
+					// Вызов прерван окончанием прогона и в статистику не входит
+					return
+				}
+
+				mu.Lock()
+				latencies = append(latencies, elapsed)
+				codes[status.Code(err).String()]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	result := summarize(method, *concurrency, time.Since(start), latencies, codes)
+	if f.output == outputJSON {
+		return printJSON(os.Stdout, result)
+	}
+	return printBench(result)
+}
+
+// summarize считает статистику по замерам задержек
+func summarize(method string, concurrency int, elapsed time.Duration, latencies []time.Duration, codes map[string]int) benchResult {
+	result := benchResult{
+		Method:      method,
+		Requests:    len(latencies),
+		Concurrency: concurrency,
+		Duration:    elapsed.Round(time.Millisecond).String(),
+		Codes:       codes,
+	}
+	if len(latencies) == 0 {
+		return result
+	}
+
+	result.RPS = float64(len(latencies)) / elapsed.Seconds()
+	slices.Sort(latencies)
+
+	var sum time.Duration
+	for _, l := range latencies {
+		sum += l
+	}
+	percentile := func(p int) string {
+		return formatLatency(latencies[(len(latencies)-1)*p/100])
+	}
+	result.Latency = latencyStats{
+		Min: formatLatency(latencies[0]),
+		Avg: formatLatency(sum / time.Duration(len(latencies))),
+		P50: percentile(50),
+		P90: percentile(90),
+		P95: percentile(95),
+		P99: percentile(99),
+		Max: formatLatency(latencies[len(latencies)-1]),
+	}
+
+	counts := make([]int, len(histogramBuckets)+1)
+	for _, l := range latencies {
+		i, _ := slices.BinarySearch(histogramBuckets, l)
+		counts[i]++
+	}
+	for i, count := range counts {
+		le := "+Inf"
+		if i < len(histogramBuckets) {
+			le = histogramBuckets[i].String()
+		}
+		result.Histogram = append(result.Histogram, bucket{LE: le, Count: count})
+	}
+	return result
+}
+
+// printBench выводит итог прогона таблицами
+func printBench(r benchResult) error {
+	summary := [][]string{
+		{"method", r.Method},
+		{"requests", strconv.Itoa(r.Requests)},
+		{"concurrency", strconv.Itoa(r.Concurrency)},
+		{"duration", r.Duration},
+		{"rps", strconv.FormatFloat(r.RPS, 'f', 1, 64)},
+		{"min", r.Latency.Min},
+		{"avg", r.Latency.Avg},
+		{"p50", r.Latency.P50},
+		{"p90", r.Latency.P90},
+		{"p95", r.Latency.P95},
+		{"p99", r.Latency.P99},
+		{"max", r.Latency.Max},
+	}
+	if err := printTable(nil, summary); err != nil {
+		return err
+	}
+
+	codes := make([]string, 0, len(r.Codes))
+	for code := range r.Codes {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	rows := make([][]string, 0, len(codes))
+	for _, code := range codes {
+		rows = append(rows, []string{code, strconv.Itoa(r.Codes[code])})
+	}
+	fmt.Println()
+	if err := printTable([]string{"CODE", "COUNT"}, rows); err != nil {
+		return err
+	}
+
+	maxCount := 0
+	for _, b := range r.Histogram {
+		maxCount = max(maxCount, b.Count)
+	}
+	rows = rows[:0]
+	for _, b := range r.Histogram {
+		bar := ""
+		if maxCount > 0 {
+			bar = strings.Repeat("█", b.Count*histogramWidth/maxCount)
+		}
+		rows = append(rows, []string{"≤ " + b.LE, strconv.Itoa(b.Count), bar})
+	}
+	fmt.Println()
+	return printTable([]string{"LATENCY", "COUNT", ""}, rows)
+}
+
+func formatLatency(d time.Duration) string {
+	return d.Round(10 * time.Microsecond).String()
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// runList выводит список сервисов или методов указанного сервиса
+func runList(args []string) error {
+	fs, f := newFlagSet("list", "[сервис]")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	source, closeSource, err := f.newSource()
+	if err != nil {
+		return err
+	}
+	defer closeSource()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	if fs.NArg() == 0 {
+		services, err := source.ListServices(ctx)
+		if err != nil {
+			return err
+		}
+		if f.output == outputJSON {
+			return printJSON(os.Stdout, map[string]interface{}{"services": services})
+		}
+		rows := make([][]string, 0, len(services))
+		for _, service := range services {
+			rows = append(rows, []string{service})
+		}
+		return printTable(nil, rows)
+	}
+
+	desc, err := source.FindSymbol(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return fmt.Errorf("%s не является сервисом", fs.Arg(0))
+	}
+
+	type methodInfo struct {
+		Name            string `json:"name"`
+		Input           string `json:"input"`
+		Output          string `json:"output"`
+		ClientStreaming bool   `json:"clientStreaming"`
+		ServerStreaming bool   `json:"serverStreaming"`
+	}
+	methods := make([]methodInfo, 0, sd.Methods().Len())
+	for i := 0; i < sd.Methods().Len(); i++ {
+		md := sd.Methods().Get(i)
+		methods = append(methods, methodInfo{
+			Name:            string(md.FullName()),
+			Input:           string(md.Input().FullName()),
+			Output:          string(md.Output().FullName()),
+			ClientStreaming: md.IsStreamingClient(),
+			ServerStreaming: md.IsStreamingServer(),
+		})
+	}
+	if f.output == outputJSON {
+		return printJSON(os.Stdout, map[string]interface{}{"service": sd.FullName(), "methods": methods})
+	}
+
+	rows := make([][]string, 0, len(methods))
+	for _, m := range methods {
+		rows = append(rows, []string{m.Name, m.Input, m.Output, streamingKind(m.ClientStreaming, m.ServerStreaming)})
+	}
+	return printTable([]string{"METHOD", "INPUT", "OUTPUT", "STREAMING"}, rows)
+}
+
+// runDescribe выводит описание сервиса, метода или сообщения
+func runDescribe(args []string) error {
+	fs, f := newFlagSet("describe", "<символ>")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("нужно указать один символ")
+	}
+
+	source, closeSource, err := f.newSource()
+	if err != nil {
+		return err
+	}
+	defer closeSource()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	name := strings.ReplaceAll(strings.TrimPrefix(fs.Arg(0), "/"), "/", ".")
+	desc, err := source.FindSymbol(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if f.output == outputJSON {
+		out, err := protojson.MarshalOptions{Multiline: true}.Marshal(descriptorProto(desc))
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	var b strings.Builder
+	writeDescriptor(&b, desc)
+	fmt.Print(b.String())
+	return nil
+}
+
+// runCall выполняет вызов метода и выводит ответ
+func runCall(args []string) error {
+	fs, f := newFlagSet("call", "<метод>")
+	data := fs.String("d", "{}", "тело запроса в JSON; @файл — из файла, @- — из stdin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("нужно указать метод")
+	}
+
+	body, err := readBody(*data)
+	if err != nil {
+		return err
+	}
+
+	client, dyn, err := f.newDynamicClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	md, err := dyn.FindMethod(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if md.IsStreamingServer() && !md.IsStreamingClient() {
+		first := true
+		return dyn.ServerStreamJSON(ctx, fs.Arg(0), body, func(msg []byte) error {
+			if !first && f.output == outputTable {
+				fmt.Println()
+			}
+			first = false
+			return printMessage(f.output, msg)
+		})
+	}
+
+	out, err := dyn.InvokeJSON(ctx, fs.Arg(0), body)
+	if err != nil {
+		return err
+	}
+	return printMessage(f.output, out)
+}
+
+func streamingKind(client, server bool) string {
+	switch {
+	case client && server:
+		return "bidi"
+	case client:
+		return "client"
+	case server:
+		return "server"
+	default:
+		return "unary"
+	}
+}
+
+// descriptorProto возвращает описание символа в виде descriptorpb
+func descriptorProto(desc protoreflect.Descriptor) proto.Message {
+	switch d := desc.(type) {
+	case protoreflect.ServiceDescriptor:
+		return protodesc.ToServiceDescriptorProto(d)
+	case protoreflect.MethodDescriptor:
+		return protodesc.ToMethodDescriptorProto(d)
+	case protoreflect.MessageDescriptor:
+		return protodesc.ToDescriptorProto(d)
+	case protoreflect.EnumDescriptor:
+		return protodesc.ToEnumDescriptorProto(d)
+	case protoreflect.FieldDescriptor:
+		return protodesc.ToFieldDescriptorProto(d)
+	default:
+		return protodesc.ToFileDescriptorProto(desc.ParentFile())
+	}
+}
+
+// writeDescriptor выводит описание символа в синтаксисе proto
+func writeDescriptor(b *strings.Builder, desc protoreflect.Descriptor) {
+	switch d := desc.(type) {
+	case protoreflect.ServiceDescriptor:
+		fmt.Fprintf(b, "service %s {\n", d.FullName())
+		for i := 0; i < d.Methods().Len(); i++ {
+			fmt.Fprintf(b, "  %s\n", methodSignature(d.Methods().Get(i)))
+		}
+		b.WriteString("}\n")
+	case protoreflect.MethodDescriptor:
+		fmt.Fprintf(b, "%s\n", methodSignature(d))
+		b.WriteString("\n")
+		writeDescriptor(b, d.Input())
+		if d.Output().FullName() != d.Input().FullName() {
+			b.WriteString("\n")
+			writeDescriptor(b, d.Output())
+		}
+	case protoreflect.MessageDescriptor:
+		writeMessage(b, d, "")
+	case protoreflect.EnumDescriptor:
+		writeEnum(b, d, "")
+	default:
+		fmt.Fprintf(b, "%s\n", desc.FullName())
+	}
+}
+
+func methodSignature(md protoreflect.MethodDescriptor) string {
+	in, out := string(md.Input().FullName()), string(md.Output().FullName())
+	if md.IsStreamingClient() {
+		in = "stream " + in
+	}
+	if md.IsStreamingServer() {
+		out = "stream " + out
+	}
+	return fmt.Sprintf("rpc %s(%s) returns (%s);", md.Name(), in, out)
+}
+
+func writeMessage(b *strings.Builder, md protoreflect.MessageDescriptor, indent string) {
+	fmt.Fprintf(b, "%smessage %s {\n", indent, md.FullName())
+	for i := 0; i < md.Fields().Len(); i++ {
+		fd := md.Fields().Get(i)
+		fmt.Fprintf(b, "%s  %s %s = %d;\n", indent, fieldType(fd), fd.Name(), fd.Number())
+	}
+	for i := 0; i < md.Enums().Len(); i++ {
+		writeEnum(b, md.Enums().Get(i), indent+"  ")
+	}
+	for i := 0; i < md.Messages().Len(); i++ {
+		if nested := md.Messages().Get(i); !nested.IsMapEntry() {
+			writeMessage(b, nested, indent+"  ")
+		}
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+func writeEnum(b *strings.Builder, ed protoreflect.EnumDescriptor, indent string) {
+	fmt.Fprintf(b, "%senum %s {\n", indent, ed.FullName())
+	for i := 0; i < ed.Values().Len(); i++ {
+		v := ed.Values().Get(i)
+		fmt.Fprintf(b, "%s  %s = %d;\n", indent, v.Name(), v.Number())
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+func fieldType(fd protoreflect.FieldDescriptor) string {
+	if fd.IsMap() {
+		return fmt.Sprintf("map<%s, %s>", kindName(fd.MapKey()), kindName(fd.MapValue()))
+	}
+	name := kindName(fd)
+	switch {
+	case fd.IsList():
+		return "repeated " + name
+	case fd.HasOptionalKeyword():
+		return "optional " + name
+	default:
+		return name
+	}
+}
+
+func kindName(fd protoreflect.FieldDescriptor) string {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return string(fd.Message().FullName())
+	case protoreflect.EnumKind:
+		return string(fd.Enum().FullName())
+	default:
+		return fd.Kind().String()
+	}
+}
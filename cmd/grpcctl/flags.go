@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/arrowwhi/go-utils/grpcclient"
+	"google.golang.org/grpc/metadata"
+)
+
+// headers — повторяемый флаг -H "ключ: значение"
+type headers []string
+
+func (h *headers) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headers) Set(value string) error {
+	if !strings.Contains(value, ":") {
+		return fmt.Errorf("заголовок %q должен быть в формате \"ключ: значение\"", value)
+	}
+	*h = append(*h, value)
+	return nil
+}
+
+// metadata преобразует заголовки в метаданные gRPC
+func (h headers) metadata() metadata.MD {
+	md := metadata.MD{}
+	for _, header := range h {
+		key, value, _ := strings.Cut(header, ":")
+		md.Append(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+	return md
+}
+
+// connFlags — общие флаги подключения для всех команд
+type connFlags struct {
+	addr           string
+	plaintext      bool
+	caFile         string
+	certFile       string
+	keyFile        string
+	serverName     string
+	token          string
+	headers        headers
+	timeout        time.Duration
+	connectTimeout time.Duration
+	retries        int
+	descriptorSet  string
+	output         string
+}
+
+// newFlagSet создает набор флагов команды с общими флагами подключения
+func newFlagSet(name, args string) (*flag.FlagSet, *connFlags) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Использование: grpcctl %s [флаги] %s\n\nФлаги:\n", name, args)
+		fs.PrintDefaults()
+	}
+
+	f := &connFlags{}
+	fs.StringVar(&f.addr, "addr", "localhost:50051", "адрес сервера host:port")
+	fs.BoolVar(&f.plaintext, "plaintext", false, "подключаться без TLS")
+	fs.StringVar(&f.caFile, "cacert", "", "CA для проверки сервера; пусто — системные корневые сертификаты")
+	fs.StringVar(&f.certFile, "cert", "", "клиентский сертификат для mTLS")
+	fs.StringVar(&f.keyFile, "key", "", "ключ клиентского сертификата для mTLS")
+	fs.StringVar(&f.serverName, "servername", "", "имя сервера для проверки сертификата")
+	fs.StringVar(&f.token, "token", "", "bearer токен для заголовка authorization")
+	fs.Var(&f.headers, "H", "метаданные запроса \"ключ: значение\" (можно повторять)")
+	fs.DurationVar(&f.timeout, "timeout", 10*time.Second, "таймаут одного вызова")
+	fs.DurationVar(&f.connectTimeout, "connect-timeout", 5*time.Second, "таймаут подключения; 0 — ленивое подключение")
+	fs.IntVar(&f.retries, "retries", 1, "число повторных попыток вызова")
+	fs.StringVar(&f.descriptorSet, "protoset", "", "файл FileDescriptorSet вместо reflection сервера")
+	fs.StringVar(&f.output, "o", "table", "формат вывода: table или json")
+	return fs, f
+}
+
+// checkOutput проверяет формат вывода
+func (f *connFlags) checkOutput() error {
+	if f.output != outputTable && f.output != outputJSON {
+		return fmt.Errorf("неизвестный формат вывода %q", f.output)
+	}
+	return nil
+}
+
+// newClient создает клиент по флагам подключения
+func (f *connFlags) newClient() (*grpcclient.Client, error) {
+	if err := f.checkOutput(); err != nil {
+		return nil, err
+	}
+
+	opts := []grpcclient.ClientOption{
+		grpcclient.WithTimeout(f.timeout),
+		grpcclient.WithMaxRetries(f.retries),
+	}
+	if f.connectTimeout > 0 {
+		opts = append(opts, grpcclient.WithEagerConnect(f.connectTimeout))
+	}
+	if !f.plaintext {
+		opts = append(opts, grpcclient.WithMTLS(f.caFile, f.certFile, f.keyFile))
+		if f.serverName != "" {
+			opts = append(opts, grpcclient.WithServerNameOverride(f.serverName))
+		}
+	}
+	if f.token != "" {
+		opts = append(opts, grpcclient.WithCredentials(grpcclient.StaticToken(f.token)))
+	}
+	if len(f.headers) > 0 {
+		opts = append(opts, grpcclient.WithDefaultMetadata(f.headers.metadata()))
+	}
+
+	return grpcclient.NewClient(f.addr, opts...)
+}
+
+// newDynamicClient создает клиент и источник описаний: файл из -protoset или reflection сервера
+func (f *connFlags) newDynamicClient() (*grpcclient.Client, *grpcclient.DynamicClient, error) {
+	client, err := f.newClient()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	source := grpcclient.NewReflectionSource(client)
+	if f.descriptorSet != "" {
+		if source, err = grpcclient.NewDescriptorSetSource(f.descriptorSet); err != nil {
+			client.Close()
+			return nil, nil, err
+		}
+	}
+	return client, grpcclient.NewDynamicClient(client, source), nil
+}
+
+// newSource возвращает источник описаний для list и describe. С -protoset
+// описания читаются из файла, и к серверу команда не подключается.
+func (f *connFlags) newSource() (grpcclient.DescriptorSource, func(), error) {
+	if f.descriptorSet != "" {
+		if err := f.checkOutput(); err != nil {
+			return nil, nil, err
+		}
+		source, err := grpcclient.NewDescriptorSetSource(f.descriptorSet)
+		if err != nil {
+			return nil, nil, err
+		}
+		return source, func() {}, nil
+	}
+
+	client, err := f.newClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	return grpcclient.NewReflectionSource(client), func() { client.Close() }, nil
+}
+
+// readBody возвращает тело запроса: строку JSON, содержимое файла (@path) или stdin (@-)
+func readBody(data string) ([]byte, error) {
+	path, ok := strings.CutPrefix(data, "@")
+	if !ok {
+		return []byte(data), nil
+	}
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// signalContext возвращает контекст, отменяемый по Ctrl+C
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
@@ -0,0 +1,54 @@
+// grpcctl — утилита для вызова и исследования gRPC сервисов на основе grpcclient.
+//
+// Примеры:
+//
+//	grpcctl list -addr localhost:50051
+//	grpcctl describe -addr localhost:50051 message_service.users.v1.UsersService/GetStatusInfo
+//	grpcctl call -addr localhost:50051 -d '{"id": 1}' message_service.users.v1.UsersService/GetStatusInfo
+//	grpcctl bench -addr localhost:50051 -n 1000 -c 10 -d '{}' message_service.users.v1.UsersService/GetStatusInfo
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `Использование: grpcctl <команда> [флаги] [аргументы]
+
+Команды:
+  list [сервис]       список сервисов или методов сервиса
+  describe <символ>   описание сервиса, метода или сообщения
+  call <метод>        вызов метода с JSON телом
+  bench <метод>       многократный вызов метода со статистикой задержек
+
+Флаги команды: grpcctl <команда> -h
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	commands := map[string]func(args []string) error{
+		"list":     runList,
+		"describe": runDescribe,
+		"call":     runCall,
+		"bench":    runBench,
+	}
+
+	name := os.Args[1]
+	run, ok := commands[name]
+	if !ok {
+		if name != "-h" && name != "-help" && name != "help" {
+			fmt.Fprintf(os.Stderr, "неизвестная команда %q\n\n", name)
+		}
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err := run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "grpcctl %s: %s\n", name, err)
+		os.Exit(1)
+	}
+}
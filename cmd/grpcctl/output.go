@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Форматы вывода
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// printJSON выводит значение в JSON с отступами
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printMessage выводит сообщение, полученное в JSON: как есть с отступами
+// или таблицей поле — значение для верхнего уровня сообщения
+func printMessage(format string, msg []byte) error {
+	if format == outputJSON {
+		var out bytes.Buffer
+		if err := json.Indent(&out, msg, "", "  "); err != nil {
+			return err
+		}
+		out.WriteByte('\n')
+		_, err := out.WriteTo(os.Stdout)
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &fields); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := make([][]string, 0, len(names))
+	for _, name := range names {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, fields[name]); err != nil {
+			return err
+		}
+		rows = append(rows, []string{name, compact.String()})
+	}
+	return printTable([]string{"FIELD", "VALUE"}, rows)
+}
+
+// printTable выводит строки таблицей с выравниванием колонок
+func printTable(header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	if len(header) > 0 {
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
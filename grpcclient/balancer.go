@@ -0,0 +1,78 @@
+package grpcclient
+
+import (
+	"math/rand/v2"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	_ "google.golang.org/grpc/balancer/leastrequest" // регистрирует политику least_request_experimental
+	"google.golang.org/grpc/resolver"
+)
+
+// Политики балансировки для WithLoadBalancingPolicy
+const (
+	// PickFirstPolicy использует первый доступный адрес
+	PickFirstPolicy = "pick_first"
+	// RoundRobinPolicy распределяет вызовы по всем адресам по очереди
+	RoundRobinPolicy = "round_robin"
+	// LeastRequestPolicy выбирает из двух случайных адресов тот, где меньше активных вызовов
+	LeastRequestPolicy = "least_request_experimental"
+	// WeightedPolicy выбирает адрес случайно пропорционально весу из Endpoint.Weight
+	WeightedPolicy = "grpcclient_weighted_random"
+)
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(WeightedPolicy, weightedPickerBuilder{}, base.Config{HealthCheck: true}))
+}
+
+type weightKey struct{}
+
+// withWeight сохраняет вес в атрибутах адреса для балансировщика
+func withWeight(addr resolver.Address, weight uint32) resolver.Address {
+	if weight == 0 {
+		weight = 1
+	}
+	addr.BalancerAttributes = addr.BalancerAttributes.WithValue(weightKey{}, weight)
+	return addr
+}
+
+func weightOf(addr resolver.Address) uint32 {
+	if weight, ok := addr.BalancerAttributes.Value(weightKey{}).(uint32); ok {
+		return weight
+	}
+	return 1
+}
+
+type weightedPickerBuilder struct{}
+
+func (weightedPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	p := &weightedPicker{}
+	for sc, scInfo := range info.ReadySCs {
+		p.total += uint64(weightOf(scInfo.Address))
+		p.subConns = append(p.subConns, sc)
+		p.bounds = append(p.bounds, p.total)
+	}
+	return p
+}
+
+// weightedPicker выбирает подключение случайно пропорционально весу
+type weightedPicker struct {
+	subConns []balancer.SubConn
+	// bounds — накопленные суммы весов
+	bounds []uint64
+	total  uint64
+}
+
+func (p *weightedPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	n := rand.Uint64N(p.total)
+	for i, bound := range p.bounds {
+		if n < bound {
+			return balancer.PickResult{SubConn: p.subConns[i]}, nil
+		}
+	}
+	return balancer.PickResult{SubConn: p.subConns[len(p.subConns)-1]}, nil
+}
@@ -0,0 +1,163 @@
+package grpcclient
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// countingServer — локальный сервер grpc.health.v1, считающий полученные вызовы
+type countingServer struct {
+	addr string
+	hits atomic.Int64
+}
+
+func startCountingServer(t *testing.T) *countingServer {
+	t.Helper()
+	s := &countingServer{}
+	srv := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		s.hits.Add(1)
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	s.addr = lis.Addr().String()
+	return s
+}
+
+func callHealth(t *testing.T, client *Client, n int) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < n; i++ {
+		if _, err := Invoke[healthpb.HealthCheckResponse](ctx, client, healthpb.Health_Check_FullMethodName, &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// warmUp вызывает сервис, пока каждый сервер не получит вызов, и обнуляет счетчики
+func warmUp(t *testing.T, client *Client, servers ...*countingServer) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		callHealth(t, client, 1)
+		ready := true
+		for _, s := range servers {
+			ready = ready && s.hits.Load() > 0
+		}
+		if ready {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("не все серверы получили вызовы")
+		}
+	}
+	for _, s := range servers {
+		s.hits.Store(0)
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	servers := []*countingServer{startCountingServer(t), startCountingServer(t), startCountingServer(t)}
+
+	client, err := NewClient("users",
+		WithStaticAddresses(servers[0].addr, servers[1].addr, servers[2].addr),
+		WithLoadBalancingPolicy(RoundRobinPolicy),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	warmUp(t, client, servers...)
+	callHealth(t, client, 30)
+	for i, s := range servers {
+		if hits := s.hits.Load(); hits != 10 {
+			t.Errorf("сервер %d получил %d вызовов, ожидалось 10", i, hits)
+		}
+	}
+}
+
+func TestWeightedPolicy(t *testing.T) {
+	light, heavy := startCountingServer(t), startCountingServer(t)
+
+	client, err := NewClient("users",
+		WithResolver(ResolverFunc(func(context.Context) ([]Endpoint, error) {
+			return []Endpoint{{Addr: light.addr, Weight: 1}, {Addr: heavy.addr, Weight: 3}}, nil
+		}), 0),
+		WithLoadBalancingPolicy(WeightedPolicy),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	warmUp(t, client, light, heavy)
+	const calls = 1000
+	callHealth(t, client, calls)
+
+	// Ожидается 75% вызовов на heavy; допускаем разброс случайного выбора
+	share := float64(heavy.hits.Load()) / calls
+	if share < 0.65 || share > 0.85 {
+		t.Fatalf("доля вызовов на сервер с весом 3: %.2f, ожидалось около 0.75", share)
+	}
+	if light.hits.Load()+heavy.hits.Load() != calls {
+		t.Fatalf("учтено %d вызовов из %d", light.hits.Load()+heavy.hits.Load(), calls)
+	}
+}
+
+func TestResolverReResolution(t *testing.T) {
+	first, second := startCountingServer(t), startCountingServer(t)
+
+	var mu sync.Mutex
+	addr := first.addr
+	resolver := ResolverFunc(func(context.Context) ([]Endpoint, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return []Endpoint{{Addr: addr}}, nil
+	})
+
+	client, err := NewClient("users", WithResolver(resolver, 20*time.Millisecond), WithLoadBalancingPolicy(RoundRobinPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	callHealth(t, client, 5)
+	if first.hits.Load() != 5 || second.hits.Load() != 0 {
+		t.Fatalf("до смены адреса: первый сервер %d, второй %d вызовов", first.hits.Load(), second.hits.Load())
+	}
+
+	mu.Lock()
+	addr = second.addr
+	mu.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for second.hits.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("вызовы не переключились на новый адрес")
+		}
+		callHealth(t, client, 1)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	first.hits.Store(0)
+	callHealth(t, client, 5)
+	if first.hits.Load() != 0 {
+		t.Fatalf("после смены адреса первый сервер получил %d вызовов", first.hits.Load())
+	}
+}
@@ -0,0 +1,344 @@
+package grpcclient
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BreakerState — состояние автоматического выключателя
+type BreakerState int
+
+const (
+	// BreakerClosed — вызовы проходят, ошибки подсчитываются
+	BreakerClosed BreakerState = iota
+	// BreakerOpen — вызовы отклоняются без обращения к сети
+	BreakerOpen
+	// BreakerHalfOpen — пропускается ограниченное число пробных вызовов
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// BreakerScope определяет, что считается отдельным выключателем
+type BreakerScope int
+
+const (
+	// ScopeTarget — один выключатель на цель клиента
+	ScopeTarget BreakerScope = iota
+	// ScopeMethod — отдельный выключатель на каждый метод каждой цели
+	ScopeMethod
+)
+
+// ErrCircuitOpen возвращается (в обертке) при отклонении вызова открытым выключателем
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError — ошибка вызова, отклоненного выключателем.
+// Соответствует статусу Unavailable и ErrCircuitOpen для errors.Is.
+type CircuitOpenError struct {
+	// Name — имя выключателя: цель или цель с методом
+	Name string
+	// State — состояние, в котором вызов был отклонен
+	State BreakerState
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("выключатель %s в состоянии %s, вызов отклонен", e.Name, e.State)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// GRPCStatus позволяет получить код Unavailable через status.FromError
+func (e *CircuitOpenError) GRPCStatus() *status.Status {
+	return status.New(codes.Unavailable, e.Error())
+}
+
+// CircuitBreakerConfig описывает условия срабатывания выключателя
+type CircuitBreakerConfig struct {
+	Scope BreakerScope
+	// ConsecutiveFailures — число ошибок подряд, после которого выключатель открывается; 0 — не проверять
+	ConsecutiveFailures int
+	// FailureRatio — доля ошибок в окне Window, после которой выключатель открывается; 0 — не проверять
+	FailureRatio float64
+	// MinRequests — минимальное число вызовов в окне для проверки FailureRatio
+	MinRequests int
+	// Window — длительность окна подсчета в закрытом состоянии
+	Window time.Duration
+	// OpenTimeout — время в открытом состоянии до перехода в полуоткрытое
+	OpenTimeout time.Duration
+	// HalfOpenMaxCalls — число пробных вызовов в полуоткрытом состоянии;
+	// при успехе всех выключатель закрывается
+	HalfOpenMaxCalls int
+	// FailureCodes — коды, которые считаются ошибками;
+	// по умолчанию Unavailable, DeadlineExceeded, Internal, Unknown, ResourceExhausted
+	FailureCodes []codes.Code
+	// OnStateChange вызывается при смене состояния
+	OnStateChange func(name string, from, to BreakerState)
+	// Registerer — реестр для метрики состояния; nil — без метрики
+	Registerer prometheus.Registerer
+}
+
+// CircuitBreaker — набор выключателей, разделенных по цели или методу
+type CircuitBreaker struct {
+	cfg   CircuitBreakerConfig
+	state *prometheus.GaugeVec
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	// pending — уведомления о смене состояния, отправляемые после снятия блокировки
+	pending []func()
+	// sweptAt — время последнего удаления неиспользуемых выключателей
+	sweptAt time.Time
+}
+
+// circuit хранит состояние одного выключателя
+type circuit struct {
+	state      BreakerState
+	generation uint64
+	openedAt   time.Time
+
+	windowStart time.Time
+	requests    int
+	failures    int
+	consecutive int
+
+	halfOpenInFlight  int
+	halfOpenSuccesses int
+
+	// lastUsed — время последнего вызова через выключатель
+	lastUsed time.Time
+}
+
+// NewCircuitBreaker создает выключатель и регистрирует метрику состояния
+func NewCircuitBreaker(cfg CircuitBreakerConfig) (*CircuitBreaker, error) {
+	if cfg.Window <= 0 {
+		cfg.Window = 10 * time.Second
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 5 * time.Second
+	}
+	if cfg.HalfOpenMaxCalls <= 0 {
+		cfg.HalfOpenMaxCalls = 1
+	}
+	if len(cfg.FailureCodes) == 0 {
+		cfg.FailureCodes = []codes.Code{
+			codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown, codes.ResourceExhausted,
+		}
+	}
+
+	cb := &CircuitBreaker{cfg: cfg, circuits: make(map[string]*circuit), sweptAt: time.Now()}
+
+	if cfg.Registerer != nil {
+		cb.state = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "grpc_client_circuit_breaker_state",
+				Help: "Состояние выключателя gRPC клиента: 0 — closed, 1 — open, 2 — half-open",
+			},
+			[]string{"name"},
+		)
+		if err := cfg.Registerer.Register(cb.state); err != nil {
+			return nil, fmt.Errorf("failed to register circuit breaker state: %w", err)
+		}
+	}
+
+	return cb, nil
+}
+
+// WithCircuitBreaker включает выключатель для унарных вызовов клиента.
+// Один CircuitBreaker можно использовать в нескольких клиентах.
+func WithCircuitBreaker(cb *CircuitBreaker) ClientOption {
+	return func(c *Client) {
+		c.breaker = cb
+	}
+}
+
+// State возвращает состояние выключателя с указанным именем: цели для ScopeTarget
+// или цели с методом для ScopeMethod, например "users:50051/pkg.Service/Method"
+func (cb *CircuitBreaker) State(name string) BreakerState {
+	cb.mu.Lock()
+	defer cb.unlock()
+
+	if ci, ok := cb.circuits[name]; ok {
+		cb.advance(name, ci, time.Now())
+		return ci.state
+	}
+	return BreakerClosed
+}
+
+// name возвращает имя выключателя для вызова в зависимости от области.
+// Метод учитывается вместе с целью: общий CircuitBreaker нескольких клиентов
+// не должен отключать метод для всех целей сразу.
+func (cb *CircuitBreaker) name(target, method string) string {
+	if cb.cfg.Scope == ScopeMethod {
+		return target + normalizeMethod(method)
+	}
+	return target
+}
+
+// allow проверяет, можно ли выполнить вызов. При успехе возвращает функцию,
+// которую нужно вызвать с результатом вызова.
+func (cb *CircuitBreaker) allow(target, method string) (func(err error), error) {
+	name := cb.name(target, method)
+	now := time.Now()
+
+	cb.mu.Lock()
+	defer cb.unlock()
+
+	ci, ok := cb.circuits[name]
+	if !ok {
+		cb.sweep(now)
+		ci = &circuit{windowStart: now}
+		cb.circuits[name] = ci
+	}
+	ci.lastUsed = now
+	cb.advance(name, ci, now)
+
+	switch ci.state {
+	case BreakerOpen:
+		return nil, &CircuitOpenError{Name: name, State: ci.state}
+	case BreakerHalfOpen:
+		if ci.halfOpenInFlight >= cb.cfg.HalfOpenMaxCalls {
+			return nil, &CircuitOpenError{Name: name, State: ci.state}
+		}
+		ci.halfOpenInFlight++
+	}
+
+	generation := ci.generation
+	return func(err error) {
+		cb.record(name, ci, generation, cb.isFailure(err))
+	}, nil
+}
+
+// sweep удаляет закрытые выключатели без вызовов дольше окна подсчета,
+// чтобы число выключателей не росло с числом целей и методов. Выполняется не чаще раза за окно.
+func (cb *CircuitBreaker) sweep(now time.Time) {
+	if now.Sub(cb.sweptAt) < cb.cfg.Window {
+		return
+	}
+	cb.sweptAt = now
+
+	for name, ci := range cb.circuits {
+		if ci.state == BreakerClosed && now.Sub(ci.lastUsed) >= cb.cfg.Window {
+			delete(cb.circuits, name)
+			if cb.state != nil {
+				cb.state.DeleteLabelValues(name)
+			}
+		}
+	}
+}
+
+func (cb *CircuitBreaker) isFailure(err error) bool {
+	return err != nil && slices.Contains(cb.cfg.FailureCodes, status.Code(err))
+}
+
+// advance переводит выключатель в полуоткрытое состояние по истечении OpenTimeout
+// и начинает новое окно подсчета в закрытом состоянии
+func (cb *CircuitBreaker) advance(name string, ci *circuit, now time.Time) {
+	switch ci.state {
+	case BreakerOpen:
+		if now.Sub(ci.openedAt) >= cb.cfg.OpenTimeout {
+			cb.setState(name, ci, BreakerHalfOpen, now)
+		}
+	case BreakerClosed:
+		if now.Sub(ci.windowStart) >= cb.cfg.Window {
+			ci.windowStart = now
+			ci.requests = 0
+			ci.failures = 0
+		}
+	}
+}
+
+// record учитывает результат вызова, разрешенного в поколении generation
+func (cb *CircuitBreaker) record(name string, ci *circuit, generation uint64, failed bool) {
+	cb.mu.Lock()
+	defer cb.unlock()
+
+	// Состояние сменилось, пока выполнялся вызов: результат уже не актуален
+	if ci.generation != generation {
+		return
+	}
+	now := time.Now()
+
+	switch ci.state {
+	case BreakerClosed:
+		ci.requests++
+		if !failed {
+			ci.consecutive = 0
+			return
+		}
+		ci.failures++
+		ci.consecutive++
+		if cb.shouldTrip(ci) {
+			cb.setState(name, ci, BreakerOpen, now)
+		}
+	case BreakerHalfOpen:
+		ci.halfOpenInFlight--
+		if failed {
+			cb.setState(name, ci, BreakerOpen, now)
+			return
+		}
+		ci.halfOpenSuccesses++
+		if ci.halfOpenSuccesses >= cb.cfg.HalfOpenMaxCalls {
+			cb.setState(name, ci, BreakerClosed, now)
+		}
+	}
+}
+
+func (cb *CircuitBreaker) shouldTrip(ci *circuit) bool {
+	if cb.cfg.ConsecutiveFailures > 0 && ci.consecutive >= cb.cfg.ConsecutiveFailures {
+		return true
+	}
+	return cb.cfg.FailureRatio > 0 &&
+		ci.requests >= cb.cfg.MinRequests &&
+		float64(ci.failures)/float64(ci.requests) >= cb.cfg.FailureRatio
+}
+
+// setState меняет состояние выключателя и сбрасывает счетчики
+func (cb *CircuitBreaker) setState(name string, ci *circuit, to BreakerState, now time.Time) {
+	from := ci.state
+	ci.state = to
+	ci.generation++
+	ci.windowStart = now
+	ci.requests, ci.failures, ci.consecutive = 0, 0, 0
+	ci.halfOpenInFlight, ci.halfOpenSuccesses = 0, 0
+	if to == BreakerOpen {
+		ci.openedAt = now
+	}
+
+	if cb.state != nil {
+		cb.state.WithLabelValues(name).Set(float64(to))
+	}
+	if cb.cfg.OnStateChange != nil {
+		cb.pending = append(cb.pending, func() { cb.cfg.OnStateChange(name, from, to) })
+	}
+}
+
+// unlock снимает блокировку и вызывает накопленные уведомления о смене состояния
+func (cb *CircuitBreaker) unlock() {
+	pending := cb.pending
+	cb.pending = nil
+	cb.mu.Unlock()
+
+	for _, notify := range pending {
+		notify()
+	}
+}
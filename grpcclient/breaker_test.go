@@ -0,0 +1,68 @@
+package grpcclient
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCircuitBreakerMethodScopePerTarget(t *testing.T) {
+	cb, err := NewCircuitBreaker(CircuitBreakerConfig{Scope: ScopeMethod, ConsecutiveFailures: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const method = "/pkg.Service/Get"
+
+	for i := 0; i < 2; i++ {
+		done, err := cb.allow("users:50051", method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		done(status.Error(codes.Unavailable, "недоступен"))
+	}
+
+	if _, err := cb.allow("users:50051", method); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("выключатель users:50051 не открылся: %v", err)
+	}
+	if _, err := cb.allow("orders:50051", method); err != nil {
+		t.Fatalf("метод другой цели отклонен: %v", err)
+	}
+	if state := cb.State("users:50051" + method); state != BreakerOpen {
+		t.Fatalf("состояние %s, ожидалось open", state)
+	}
+}
+
+func TestCircuitBreakerSweepsIdleCircuits(t *testing.T) {
+	cb, err := NewCircuitBreaker(CircuitBreakerConfig{Scope: ScopeMethod, ConsecutiveFailures: 1, Window: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done, _ := cb.allow("users:50051", "/pkg.Service/Get")
+	done(nil)
+	done, _ = cb.allow("users:50051", "/pkg.Service/Broken")
+	done(status.Error(codes.Unavailable, "недоступен"))
+
+	time.Sleep(20 * time.Millisecond)
+	if _, err := cb.allow("users:50051", "/pkg.Service/Other"); err != nil {
+		t.Fatal(err)
+	}
+
+	cb.mu.Lock()
+	_, idle := cb.circuits["users:50051/pkg.Service/Get"]
+	_, open := cb.circuits["users:50051/pkg.Service/Broken"]
+	count := len(cb.circuits)
+	cb.mu.Unlock()
+	if idle {
+		t.Fatal("неиспользуемый закрытый выключатель не удален")
+	}
+	if !open {
+		t.Fatal("открытый выключатель удален")
+	}
+	if count != 2 {
+		t.Fatalf("осталось %d выключателей, ожидалось 2", count)
+	}
+}
@@ -0,0 +1,292 @@
+package grpcclient
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultCacheEntries — размер кэша по умолчанию
+const defaultCacheEntries = 1000
+
+// Результаты обращения к кэшу для метрики grpc_client_cache_requests_total
+const (
+	cacheHit   = "hit"
+	cacheStale = "stale"
+	cacheMiss  = "miss"
+)
+
+// CacheConfig описывает кэширование ответов
+type CacheConfig struct {
+	// Methods — кэшируемые методы и время жизни ответа для каждого
+	Methods map[string]time.Duration
+	// MaxEntries — максимальное число ответов в кэше; при превышении вытесняются
+	// давно не использованные. По умолчанию 1000.
+	MaxEntries int
+	// StaleWhileRevalidate — время после истечения TTL, в течение которого возвращается
+	// устаревший ответ, а новый запрашивается в фоне; 0 — устаревшие ответы не используются
+	StaleWhileRevalidate time.Duration
+	// FetchTimeout ограничивает общий запрос за ответом, который не отменяется вместе
+	// с вызывающим. 0 — используется дедлайн контекста вызова, начавшего запрос.
+	FetchTimeout time.Duration
+	// KeyMetadata — исходящие метаданные, входящие в ключ кэша, например authorization
+	// или x-tenant-id. Остальные метаданные не учитываются.
+	KeyMetadata []string
+	// Registerer — реестр для метрик кэша; nil — без метрик
+	Registerer prometheus.Registerer
+}
+
+// Cache хранит ответы идемпотентных методов. Ключ — метод, детерминированно
+// сериализованный запрос и метаданные из CacheConfig.KeyMetadata. Одновременные
+// одинаковые вызовы объединяются в один, ошибки не кэшируются.
+type Cache struct {
+	cfg     CacheConfig
+	methods map[string]time.Duration
+	group   singleflight.Group
+
+	requests *prometheus.CounterVec
+	size     prometheus.Gauge
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+// cacheEntry — ответ в кэше
+type cacheEntry struct {
+	key        string
+	resp       proto.Message
+	expires    time.Time
+	staleUntil time.Time
+}
+
+// NewCache создает кэш ответов и регистрирует его метрики
+func NewCache(cfg CacheConfig) (*Cache, error) {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = defaultCacheEntries
+	}
+
+	c := &Cache{
+		cfg:     cfg,
+		methods: make(map[string]time.Duration, len(cfg.Methods)),
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+	for method, ttl := range cfg.Methods {
+		c.methods[normalizeMethod(method)] = ttl
+	}
+
+	if cfg.Registerer != nil {
+		c.requests = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_client_cache_requests_total",
+				Help: "Количество обращений к кэшу ответов gRPC клиента по результату: hit, stale, miss",
+			},
+			[]string{"method", "result"},
+		)
+		c.size = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "grpc_client_cache_entries",
+			Help: "Количество ответов в кэше gRPC клиента",
+		})
+		if err := cfg.Registerer.Register(c.requests); err != nil {
+			return nil, fmt.Errorf("failed to register cache requests: %w", err)
+		}
+		if err := cfg.Registerer.Register(c.size); err != nil {
+			return nil, fmt.Errorf("failed to register cache entries: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// WithCache включает кэширование ответов методов из CacheConfig.Methods.
+// Исходящие метаданные не входят в ключ, кроме перечисленных в CacheConfig.KeyMetadata:
+// если ответ зависит от пользователя или арендатора, добавьте соответствующие заголовки,
+// иначе ответ, полученный с одними учетными данными, будет возвращен вызовам с другими.
+func WithCache(cache *Cache) ClientOption {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// Purge удаляет все ответы из кэша
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.updateSize()
+}
+
+// do отвечает на вызов из кэша или выполняет fetch и сохраняет ответ.
+// Возвращает false, если метод не кэшируется.
+func (c *Cache) do(ctx context.Context, method string, req, resp interface{}, fetch func(ctx context.Context, resp proto.Message) error) (bool, error) {
+	method = normalizeMethod(method)
+	ttl, ok := c.methods[method]
+	if !ok {
+		return false, nil
+	}
+	reqMsg, ok := req.(proto.Message)
+	if !ok {
+		return false, nil
+	}
+	respMsg, ok := resp.(proto.Message)
+	if !ok {
+		return false, nil
+	}
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(reqMsg)
+	if err != nil {
+		return false, nil
+	}
+	key := c.key(ctx, method, data)
+
+	if cached, fresh := c.get(key, time.Now()); cached != nil {
+		if fresh {
+			c.observe(method, cacheHit)
+		} else {
+			c.observe(method, cacheStale)
+			go c.load(ctx, key, ttl, respMsg, fetch)
+		}
+		proto.Reset(respMsg)
+		proto.Merge(respMsg, cached)
+		return true, nil
+	}
+
+	c.observe(method, cacheMiss)
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		return c.fetch(ctx, key, ttl, respMsg, fetch)
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return true, res.Err
+		}
+		proto.Reset(respMsg)
+		proto.Merge(respMsg, res.Val.(proto.Message))
+		return true, nil
+	case <-ctx.Done():
+		return true, wrapError(status.FromContextError(ctx.Err()).Err())
+	}
+}
+
+// key строит ключ кэша из метода, метаданных KeyMetadata и сериализованного запроса
+func (c *Cache) key(ctx context.Context, method string, data []byte) string {
+	var b strings.Builder
+	b.WriteString(method)
+	if len(c.cfg.KeyMetadata) > 0 {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		for _, name := range c.cfg.KeyMetadata {
+			b.WriteByte(0)
+			b.WriteString(strings.Join(md.Get(name), "\x01"))
+		}
+	}
+	b.WriteByte(0)
+	b.Write(data)
+	return b.String()
+}
+
+// fetchContext возвращает контекст общего запроса: без отмены, так как его результат
+// ждут и другие вызывающие, но с FetchTimeout или дедлайном вызова, начавшего запрос
+func (c *Cache) fetchContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	ctx = context.WithoutCancel(ctx)
+	if c.cfg.FetchTimeout > 0 {
+		return context.WithTimeout(ctx, c.cfg.FetchTimeout)
+	}
+	if ok {
+		return context.WithDeadline(ctx, deadline)
+	}
+	return context.WithCancel(ctx)
+}
+
+// load обновляет устаревший ответ; одновременные обновления одного ключа объединяются
+func (c *Cache) load(ctx context.Context, key string, ttl time.Duration, template proto.Message, fetch func(ctx context.Context, resp proto.Message) error) {
+	_, _, _ = c.group.Do(key, func() (interface{}, error) {
+		return c.fetch(ctx, key, ttl, template, fetch)
+	})
+}
+
+// fetch выполняет вызов и сохраняет успешный ответ
+func (c *Cache) fetch(ctx context.Context, key string, ttl time.Duration, template proto.Message, fetch func(ctx context.Context, resp proto.Message) error) (proto.Message, error) {
+	ctx, cancel := c.fetchContext(ctx)
+	defer cancel()
+
+	resp := template.ProtoReflect().New().Interface()
+	if err := fetch(ctx, resp); err != nil {
+		return nil, err
+	}
+	c.put(key, resp, ttl, time.Now())
+	return resp, nil
+}
+
+// get возвращает ответ из кэша и признак его свежести.
+// Ответ, устаревший больше чем на StaleWhileRevalidate, удаляется.
+func (c *Cache) get(key string, now time.Time) (proto.Message, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if now.After(entry.staleUntil) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.resp, now.Before(entry.expires)
+}
+
+// put сохраняет ответ и вытесняет давно не использованные при превышении размера
+func (c *Cache) put(key string, resp proto.Message, ttl time.Duration, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cacheEntry{
+		key:        key,
+		resp:       resp,
+		expires:    now.Add(ttl),
+		staleUntil: now.Add(ttl + c.cfg.StaleWhileRevalidate),
+	}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.cfg.MaxEntries {
+		c.remove(c.lru.Back())
+	}
+	c.updateSize()
+}
+
+func (c *Cache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).key)
+	c.updateSize()
+}
+
+func (c *Cache) updateSize() {
+	if c.size != nil {
+		c.size.Set(float64(c.lru.Len()))
+	}
+}
+
+func (c *Cache) observe(method, result string) {
+	if c.requests != nil {
+		c.requests.WithLabelValues(method, result).Inc()
+	}
+}
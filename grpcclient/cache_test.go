@@ -0,0 +1,256 @@
+package grpcclient
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+const cachedMethod = "/pkg.Service/Cached"
+
+// newCachedClient создает клиент с кэшем метода cachedMethod, обработчик которого
+// возвращает SERVING на первый вызов и NOT_SERVING на последующие
+func newCachedClient(t *testing.T, cfg CacheConfig) (*Client, *Cache, *atomic.Int64) {
+	t.Helper()
+	if cfg.Methods == nil {
+		cfg.Methods = map[string]time.Duration{cachedMethod: time.Hour}
+	}
+	cache, err := NewCache(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int64
+	client := NewMockClient(WithCache(cache))
+	client.AddHandler(cachedMethod, func(ctx context.Context, req interface{}) (interface{}, error) {
+		if calls.Add(1) == 1 {
+			return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+		}
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	})
+	return client, cache, &calls
+}
+
+func TestCacheHit(t *testing.T) {
+	client, _, calls := newCachedClient(t, CacheConfig{})
+
+	for i := 0; i < 3; i++ {
+		got, err := checkHealth(context.Background(), client, cachedMethod, "users")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != healthpb.HealthCheckResponse_SERVING {
+			t.Fatalf("вызов %d: статус %v, ожидался ответ из кэша", i+1, got)
+		}
+	}
+	if _, err := checkHealth(context.Background(), client, cachedMethod, "orders"); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("вызовов обработчика %d, ожидалось 2: по одному на запрос", n)
+	}
+}
+
+func TestCacheTTLExpiry(t *testing.T) {
+	cache, err := NewCache(CacheConfig{StaleWhileRevalidate: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	cache.put("key", &healthpb.HealthCheckResponse{}, time.Minute, now)
+
+	if resp, fresh := cache.get("key", now.Add(30*time.Second)); resp == nil || !fresh {
+		t.Fatal("до истечения TTL ответ должен быть свежим")
+	}
+	if resp, fresh := cache.get("key", now.Add(90*time.Second)); resp == nil || fresh {
+		t.Fatal("в пределах StaleWhileRevalidate ответ должен быть устаревшим")
+	}
+	if resp, _ := cache.get("key", now.Add(3*time.Minute)); resp != nil {
+		t.Fatal("после StaleWhileRevalidate ответ должен быть удален")
+	}
+	if resp, _ := cache.get("key", now); resp != nil {
+		t.Fatal("удаленный ответ вернулся из кэша")
+	}
+}
+
+func TestCacheLRUEviction(t *testing.T) {
+	cache, err := NewCache(CacheConfig{MaxEntries: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	cache.put("a", &healthpb.HealthCheckResponse{}, time.Hour, now)
+	cache.put("b", &healthpb.HealthCheckResponse{}, time.Hour, now)
+	// Обращение к a делает давно не использованным b
+	if resp, _ := cache.get("a", now); resp == nil {
+		t.Fatal("a отсутствует в кэше")
+	}
+	cache.put("c", &healthpb.HealthCheckResponse{}, time.Hour, now)
+
+	for key, present := range map[string]bool{"a": true, "b": false, "c": true} {
+		if resp, _ := cache.get(key, now); (resp != nil) != present {
+			t.Fatalf("%s в кэше: %v, ожидалось %v", key, resp != nil, present)
+		}
+	}
+}
+
+func TestCacheSingleflight(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cache, err := NewCache(CacheConfig{Methods: map[string]time.Duration{cachedMethod: time.Hour}, Registerer: reg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls atomic.Int64
+	release := make(chan struct{})
+	client := NewMockClient(WithCache(cache))
+	client.AddHandler(cachedMethod, func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls.Add(1)
+		<-release
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+	})
+
+	const callers = 10
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := checkHealth(context.Background(), client, cachedMethod, ""); err != nil || got != healthpb.HealthCheckResponse_SERVING {
+				t.Errorf("ответ (%v, %v)", got, err)
+			}
+		}()
+	}
+
+	// Обработчик отпускается, когда все вызывающие промахнулись мимо кэша и ждут общий запрос
+	misses := cache.requests.WithLabelValues(cachedMethod, cacheMiss)
+	deadline := time.Now().Add(5 * time.Second)
+	for testutil.ToFloat64(misses) < callers {
+		if time.Now().After(deadline) {
+			t.Fatal("не все вызовы дошли до кэша")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("вызовов обработчика %d, ожидался 1", n)
+	}
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	client, _, calls := newCachedClient(t, CacheConfig{
+		Methods:              map[string]time.Duration{cachedMethod: 20 * time.Millisecond},
+		StaleWhileRevalidate: time.Hour,
+	})
+
+	if got, err := checkHealth(context.Background(), client, cachedMethod, ""); err != nil || got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("первый вызов: (%v, %v)", got, err)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	// Устаревший ответ возвращается сразу, новый запрашивается в фоне
+	if got, err := checkHealth(context.Background(), client, cachedMethod, ""); err != nil || got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("устаревший ответ: (%v, %v)", got, err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for calls.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("ответ не обновлен в фоне")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for {
+		got, err := checkHealth(context.Background(), client, cachedMethod, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == healthpb.HealthCheckResponse_NOT_SERVING {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("обновленный ответ не попал в кэш")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("вызовов обработчика %d, ожидалось 2", n)
+	}
+}
+
+func TestCacheKeyMetadata(t *testing.T) {
+	client, _, calls := newCachedClient(t, CacheConfig{KeyMetadata: []string{"x-tenant"}})
+
+	call := func(tenant, user string) healthpb.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-tenant", tenant, "x-user", user)
+		got, err := checkHealth(ctx, client, cachedMethod, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	if call("acme", "1") != healthpb.HealthCheckResponse_SERVING {
+		t.Fatal("первый вызов должен дойти до обработчика")
+	}
+	// Метаданные вне KeyMetadata не влияют на ключ
+	if call("acme", "2") != healthpb.HealthCheckResponse_SERVING {
+		t.Fatal("вызов того же арендатора должен получить ответ из кэша")
+	}
+	if call("globex", "1") != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatal("вызов другого арендатора не должен получить чужой ответ")
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("вызовов обработчика %d, ожидалось 2", n)
+	}
+}
+
+func TestCacheFetchBounded(t *testing.T) {
+	tests := []struct {
+		name         string
+		fetchTimeout time.Duration
+		callTimeout  time.Duration
+	}{
+		{"дедлайн вызова", 0, 50 * time.Millisecond},
+		{"FetchTimeout", 50 * time.Millisecond, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := NewCache(CacheConfig{
+				Methods:      map[string]time.Duration{cachedMethod: time.Hour},
+				FetchTimeout: tt.fetchTimeout,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			finished := make(chan struct{})
+			client := NewMockClient(WithCache(cache), WithTimeout(time.Hour))
+			client.AddHandler(cachedMethod, func(ctx context.Context, req interface{}) (interface{}, error) {
+				defer close(finished)
+				<-ctx.Done()
+				return nil, ctx.Err()
+			})
+
+			ctx := context.Background()
+			if tt.callTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.callTimeout)
+				defer cancel()
+			}
+			go func() { _, _ = checkHealth(ctx, client, cachedMethod, "") }()
+			select {
+			case <-finished:
+			case <-time.After(5 * time.Second):
+				t.Fatal("общий запрос не ограничен по времени")
+			}
+		})
+	}
+}
@@ -3,14 +3,17 @@ package grpcclient
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
+	"github.com/arrowwhi/go-utils/grpcclient/interceptors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 // Client представляет gRPC клиент с настраиваемыми опциями
@@ -20,6 +23,34 @@ type Client struct {
 	maxRetries     int
 	methodHandlers map[string]MethodHandler
 	metadata       metadata.MD
+	tls            *tlsOptions
+	connect        connectOptions
+	resolver       *resolverBuilder
+	target         string
+	breaker        *CircuitBreaker
+	hedger         *Hedger
+	limiter        *Limiter
+	credentials    CredentialsProvider
+	metrics        *ClientMetrics
+	tracing        *tracing
+	recorder       *Recorder
+	replayer       *Replayer
+	cache          *Cache
+	faults         *FaultInjector
+	requestID      bool
+
+	idempotentMethods map[string]struct{}
+
+	retryPolicy         *RetryPolicy
+	methodRetryPolicies map[string]RetryPolicy
+
+	unaryInterceptors  []grpc.UnaryClientInterceptor
+	streamInterceptors []grpc.StreamClientInterceptor
+	dialOptions        []grpc.DialOption
+
+	mockMu      sync.Mutex
+	mockScripts map[string]*mockScript
+	calls       []Call
 }
 
 // ClientOption определяет функцию для настройки клиента
@@ -28,45 +59,74 @@ type ClientOption func(*Client)
 // MethodHandler определяет обработчик метода gRPC
 type MethodHandler func(ctx context.Context, req interface{}) (interface{}, error)
 
-type Config struct {
-	Host string
-	Port int
-}
-
 // NewClient создает новый gRPC клиент
 func NewClient(target string, opts ...ClientOption) (*Client, error) {
-	c := &Client{
-		timeout:        5 * time.Second,
-		maxRetries:     1,
-		methodHandlers: make(map[string]MethodHandler),
-		metadata:       metadata.MD{},
-	}
+	c := newClient(opts...)
+	c.target = target
 
-	// Применяем опции конфигурации
-	for _, opt := range opts {
-		opt(c)
+	creds := insecure.NewCredentials()
+	if c.tls != nil {
+		tlsCreds, err := newTransportCredentials(*c.tls)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка настройки TLS: %w", err)
+		}
+		creds = tlsCreds
 	}
 
 	// Создаем соединение с дополнительными опциями
 	var err error
-	c.conn, err = grpc.NewClient(
-		target,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	dialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
 		grpc.WithDefaultCallOptions(grpc.WaitForReady(true)),
-	)
+		grpc.WithChainUnaryInterceptor(c.unaryInterceptors...),
+		grpc.WithChainStreamInterceptor(c.streamInterceptors...),
+	}
+	if c.credentials != nil {
+		if c.tls == nil {
+			return nil, fmt.Errorf("токен авторизации передается только по TLS: добавьте WithTLS или WithMTLS")
+		}
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(perRPCCredentials{provider: c.credentials}))
+	}
+	if c.resolver != nil {
+		// Адреса поставляет Resolver клиента, target остается именем сервиса
+		target = fmt.Sprintf("%s:///%s", resolverScheme, target)
+		dialOptions = append(dialOptions, grpc.WithResolvers(c.resolver))
+	}
+	c.conn, err = grpc.NewClient(target, append(dialOptions, c.dialOptions...)...)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка создания соединения: %w", err)
 	}
 
-	// Проверяем состояние соединения
-	if c.conn.GetState() != connectivity.Ready {
+	// Проверяем состояние соединения, если клиент настроен на немедленное подключение
+	if err := c.connectOnCreate(); err != nil {
 		c.Close() // Закрываем соединение, если оно не установлено
-		return nil, fmt.Errorf("соединение не готово, текущее состояние: %v", c.conn.GetState())
+		return nil, err
 	}
 
 	return c, nil
 }
 
+// newClient создает клиент с настройками по умолчанию и применяет опции
+func newClient(opts ...ClientOption) *Client {
+	c := &Client{
+		timeout:        5 * time.Second,
+		maxRetries:     1,
+		methodHandlers: make(map[string]MethodHandler),
+		metadata:       metadata.MD{},
+
+		methodRetryPolicies: make(map[string]RetryPolicy),
+		mockScripts:         make(map[string]*mockScript),
+		idempotentMethods:   make(map[string]struct{}),
+	}
+
+	// Применяем опции конфигурации
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
 // WithTimeout устанавливает таймаут для клиента
 func WithTimeout(timeout time.Duration) ClientOption {
 	return func(c *Client) {
@@ -81,9 +141,36 @@ func WithMaxRetries(maxRetries int) ClientOption {
 	}
 }
 
-// AddHandler добавляет пользовательский обработчик для метода
-func (c *Client) AddHandler(method string, handler MethodHandler) {
-	c.methodHandlers[method] = handler
+// WithUnaryInterceptors добавляет интерцепторы унарных вызовов.
+// Интерцепторы вызываются для каждой попытки в порядке добавления.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryClientInterceptor) ClientOption {
+	return func(c *Client) {
+		c.unaryInterceptors = append(c.unaryInterceptors, interceptors...)
+	}
+}
+
+// WithStreamInterceptors добавляет интерцепторы потоковых вызовов
+func WithStreamInterceptors(interceptors ...grpc.StreamClientInterceptor) ClientOption {
+	return func(c *Client) {
+		c.streamInterceptors = append(c.streamInterceptors, interceptors...)
+	}
+}
+
+// WithRequestID добавляет x-request-id к каждому вызову и потоку клиента.
+// Идентификатор создается один раз на вызов, поэтому повторы и хеджированные
+// копии вызова передают один и тот же x-request-id.
+func WithRequestID() ClientOption {
+	return func(c *Client) {
+		c.requestID = true
+	}
+}
+
+// WithDialOptions добавляет произвольные опции соединения.
+// Они применяются последними и могут переопределить настройки клиента.
+func WithDialOptions(opts ...grpc.DialOption) ClientOption {
+	return func(c *Client) {
+		c.dialOptions = append(c.dialOptions, opts...)
+	}
 }
 
 // Request представляет gRPC запрос
@@ -94,6 +181,7 @@ type Request struct {
 	response interface{}
 	ctx      context.Context
 	metadata metadata.MD
+	opts     []grpc.CallOption
 }
 
 // NewRequest создает новый запрос
@@ -119,62 +207,163 @@ func (r *Request) WithContext(ctx context.Context) *Request {
 	return r
 }
 
+// Idempotent помечает запрос как идемпотентный, разрешая для него хеджирование
+func (r *Request) Idempotent() *Request {
+	r.opts = append(r.opts, Idempotent())
+	return r
+}
+
 // Do выполняет gRPC запрос с поддержкой повторных попыток
 func (r *Request) Do() (interface{}, error) {
 	if r.ctx == nil {
 		r.ctx = context.Background()
 	}
 
-	if len(r.metadata) > 0 {
-		r.ctx = metadata.NewOutgoingContext(r.ctx, r.metadata)
+	if err := r.client.invoke(r.ctx, r.method, r.body, r.response, r.metadata, r.opts...); err != nil {
+		return nil, err
 	}
+	return r.response, nil
+}
 
-	ctx, cancel := context.WithTimeout(r.ctx, r.client.timeout)
-	defer cancel()
+// invoke выполняет унарный вызов с повторными попытками по политике метода.
+// Метаданные md объединяются с исходящими метаданными контекста.
+func (c *Client) invoke(ctx context.Context, method string, req, resp interface{}, md metadata.MD, opts ...grpc.CallOption) error {
+	if len(md) > 0 {
+		existing, _ := metadata.FromOutgoingContext(ctx)
+		ctx = metadata.NewOutgoingContext(ctx, metadata.Join(md, existing))
+	}
+	if c.requestID {
+		ctx = interceptors.WithRequestID(ctx)
+	}
 
-	var lastErr error
-	for retry := 0; retry <= r.client.maxRetries; retry++ {
-		// Используем r.response как контейнер для ответа
-		err := r.client.conn.Invoke(ctx, r.method, r.body, r.response)
+	if c.cache != nil {
+		handled, err := c.cache.do(ctx, method, req, resp, func(ctx context.Context, resp proto.Message) error {
+			return c.invokeObserved(ctx, method, req, resp, opts)
+		})
+		if handled {
+			return err
+		}
+	}
+	return c.invokeObserved(ctx, method, req, resp, opts)
+}
 
+// invokeObserved выполняет вызов с повторами, записывая метрики и спан вызова
+func (c *Client) invokeObserved(ctx context.Context, method string, req, resp interface{}, opts []grpc.CallOption) error {
+	ctx, span := c.tracing.start(ctx, c.target, method)
+	start := time.Now()
+	attempts, err := c.invokeWithRetries(ctx, method, req, resp, opts)
+	c.metrics.observe(method, start, attempts, err)
+	// Записывается итог логического вызова, а не каждая попытка или хедж
+	if c.replayer == nil && !c.mocked(method) {
+		c.recorder.record(ctx, method, req, resp, err)
+	}
+	endSpan(span, err)
+	return err
+}
+
+// invokeWithRetries выполняет вызов по политике повторов метода.
+// Возвращает число выполненных попыток.
+func (c *Client) invokeWithRetries(ctx context.Context, method string, req, resp interface{}, opts []grpc.CallOption) (int, error) {
+	policy := c.retryPolicyFor(method)
+	if policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		defer cancel()
+	}
+
+	attemptTimeout := policy.PerAttemptTimeout
+	if attemptTimeout <= 0 {
+		attemptTimeout = c.timeout
+	}
+
+	if c.limiter != nil {
+		release, err := c.limiter.acquire(ctx, method)
+		if err != nil {
+			return 0, wrapError(err)
+		}
+		defer release()
+	}
+
+	// Ошибка внедряется один раз на логический вызов, а не в каждую попытку или хедж
+	if _, err := c.faults.inject(ctx, method); err != nil {
+		return 1, wrapError(err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		// Открытый выключатель отклоняет вызов сразу, без повторов
+		var done func(error)
+		if c.breaker != nil {
+			var err error
+			if done, err = c.breaker.allow(c.target, method); err != nil {
+				return attempt, wrapError(err)
+			}
+		}
+
+		var trailer metadata.MD
+		err := c.invokeOnce(ctx, policy, attemptTimeout, method, req, resp, &trailer, opts)
+		if done != nil {
+			done(err)
+		}
 		if err == nil {
-			return r.response, nil
+			policy.Budget.onSuccess()
+			return attempt + 1, nil
 		}
 
 		st, ok := status.FromError(err)
-		if !ok {
-			return nil, fmt.Errorf("неизвестная ошибка: %w", err)
+		if !ok || !policy.retryable(st.Code()) || attempt+1 >= policy.MaxAttempts || ctx.Err() != nil {
+			return attempt + 1, wrapError(err)
 		}
-
-		if isRetryable(st.Code()) && retry < r.client.maxRetries {
-			time.Sleep(calculateBackoff(retry))
-			continue
+		if !policy.Budget.onFailure() {
+			return attempt + 1, wrapError(err)
 		}
 
-		lastErr = fmt.Errorf("ошибка gRPC [%s]: %w", st.Code(), err)
-		break
+		delay, ok := policy.retryDelay(attempt, trailer)
+		if !ok {
+			return attempt + 1, wrapError(err)
+		}
+		c.tracing.retry(ctx, attempt, delay, err)
+		if err := sleepContext(ctx, delay); err != nil {
+			return attempt + 1, wrapError(err)
+		}
 	}
+}
 
-	return nil, lastErr
+// invokeOnce выполняет попытку вызова; для идемпотентных вызовов — с хеджированием
+func (c *Client) invokeOnce(ctx context.Context, policy RetryPolicy, timeout time.Duration, method string, req, resp interface{}, trailer *metadata.MD, opts []grpc.CallOption) error {
+	if c.hedger != nil && c.isIdempotent(method, opts) {
+		if msg, ok := resp.(proto.Message); ok {
+			return c.hedger.invoke(ctx, c, policy, timeout, method, req, msg, trailer, opts)
+		}
+	}
+	return c.invokeAttempt(ctx, timeout, method, req, resp, append(slices.Clip(opts), grpc.Trailer(trailer))...)
 }
 
-// isRetryable проверяет, можно ли повторить запрос при данной ошибке
-func isRetryable(code codes.Code) bool {
-	switch code {
-	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
-		return true
-	default:
-		return false
+// invokeAttempt выполняет одну попытку вызова со своим таймаутом
+func (c *Client) invokeAttempt(ctx context.Context, timeout time.Duration, method string, req, resp interface{}, opts ...grpc.CallOption) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// При Unauthenticated токен мог устареть: сбрасываем его и повторяем вызов один раз
+	err := c.call(ctx, method, req, resp, opts...)
+	if c.credentials != nil && status.Code(err) == codes.Unauthenticated {
+		c.credentials.Invalidate()
+		err = c.call(ctx, method, req, resp, opts...)
 	}
+	return err
 }
 
-// calculateBackoff вычисляет время ожидания между повторными попытками
-func calculateBackoff(retry int) time.Duration {
-	backoff := time.Duration(1<<uint(retry)) * time.Second
-	if backoff > 30*time.Second {
-		backoff = 30 * time.Second
+// call выполняет вызов через зарегистрированный обработчик или по сети
+func (c *Client) call(ctx context.Context, method string, req, resp interface{}, opts ...grpc.CallOption) error {
+	// Воспроизведение записи и зарегистрированные обработчики заменяют сетевой вызов
+	if c.replayer != nil {
+		return c.replayer.replay(ctx, method, req, resp)
 	}
-	return backoff
+	if handled, err := c.invokeMock(ctx, method, req, resp); handled {
+		return err
+	}
+
+	// Используем resp как контейнер для ответа
+	return c.conn.Invoke(ctx, method, req, resp, opts...)
 }
 
 // Close закрывает соединение с gRPC сервером
@@ -0,0 +1,80 @@
+package grpcclient
+
+import (
+	"fmt"
+
+	"github.com/arrowwhi/go-utils/grpcclient/grpcclient_config"
+	"google.golang.org/grpc/metadata"
+)
+
+// Config — адрес сервера.
+//
+// Deprecated: используйте grpcclient_config.GrpcclientConfig и NewClientFromConfig.
+type Config struct {
+	Host string
+	Port int
+}
+
+// NewClientFromConfig создает клиент по конфигурации из переменных окружения.
+// Опции opts применяются после опций из конфигурации и могут их переопределить.
+func NewClientFromConfig(cfg grpcclient_config.GrpcclientConfig, opts ...ClientOption) (*Client, error) {
+	cfgOpts, err := configOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(cfg.Target, append(cfgOpts, opts...)...)
+}
+
+// configOptions преобразует конфигурацию в опции клиента
+func configOptions(cfg grpcclient_config.GrpcclientConfig) ([]ClientOption, error) {
+	// Нулевые значения (конфигурация задана литералом, а не через envconfig)
+	// оставляют значения клиента по умолчанию
+	opts := []ClientOption{
+		WithMaxMessageSize(cfg.MaxRecvMsgSize, cfg.MaxSendMsgSize),
+	}
+	if cfg.Timeout > 0 {
+		opts = append(opts, WithTimeout(cfg.Timeout))
+	}
+	if cfg.MaxRetries > 0 {
+		opts = append(opts, WithMaxRetries(cfg.MaxRetries))
+	}
+
+	if cfg.ConnectTimeout > 0 {
+		opts = append(opts, WithEagerConnect(cfg.ConnectTimeout))
+	}
+
+	if cfg.TLSEnabled || cfg.TLSCAFile != "" || cfg.TLSCertFile != "" {
+		opts = append(opts, WithMTLS(cfg.TLSCAFile, cfg.TLSCertFile, cfg.TLSKeyFile))
+		if cfg.TLSServerName != "" {
+			opts = append(opts, WithServerNameOverride(cfg.TLSServerName))
+		}
+		if cfg.TLSReloadInterval > 0 {
+			opts = append(opts, WithCertReload(cfg.TLSReloadInterval))
+		}
+	}
+
+	if cfg.KeepaliveTime > 0 {
+		opts = append(opts, WithKeepalive(cfg.KeepaliveTime, cfg.KeepaliveTimeout, cfg.KeepalivePermitWithoutStream))
+	}
+	if cfg.Compression != "" {
+		opts = append(opts, WithCompression(cfg.Compression))
+	}
+	if len(cfg.Metadata) > 0 {
+		opts = append(opts, WithDefaultMetadata(metadata.New(cfg.Metadata)))
+	}
+	if len(cfg.Addresses) > 0 {
+		opts = append(opts, WithStaticAddresses(cfg.Addresses...))
+	}
+	if cfg.LoadBalancingPolicy != "" {
+		opts = append(opts, WithLoadBalancingPolicy(cfg.LoadBalancingPolicy))
+	}
+	if cfg.FaultInjection != "" {
+		rules, err := ParseFaultRules(cfg.FaultInjection)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка разбора FAULT_INJECTION: %w", err)
+		}
+		opts = append(opts, WithFaultInjection(NewFaultInjector(FaultInjectorConfig{Rules: rules, EnvMode: cfg.EnvMode})))
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,39 @@
+package grpcclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arrowwhi/go-utils/grpcclient/grpcclient_config"
+)
+
+func TestNewClientFromConfigLiteralKeepsDefaults(t *testing.T) {
+	client, err := NewClientFromConfig(grpcclient_config.GrpcclientConfig{Target: "localhost:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if client.timeout != 5*time.Second {
+		t.Fatalf("таймаут попытки %v, ожидался таймаут по умолчанию 5s", client.timeout)
+	}
+	if client.maxRetries != 1 {
+		t.Fatalf("число повторов %d, ожидалось значение по умолчанию 1", client.maxRetries)
+	}
+}
+
+func TestNewClientFromConfig(t *testing.T) {
+	client, err := NewClientFromConfig(grpcclient_config.GrpcclientConfig{
+		Target:     "localhost:0",
+		Timeout:    time.Second,
+		MaxRetries: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if client.timeout != time.Second || client.maxRetries != 3 {
+		t.Fatalf("таймаут %v и число повторов %d не взяты из конфигурации", client.timeout, client.maxRetries)
+	}
+}
@@ -0,0 +1,105 @@
+package grpcclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// connectOptions определяет, как NewClient устанавливает соединение
+type connectOptions struct {
+	eager         bool
+	timeout       time.Duration
+	healthCheck   bool
+	healthService string
+}
+
+// WithLazyConnect откладывает установку соединения до первого вызова (режим по умолчанию)
+func WithLazyConnect() ClientOption {
+	return func(c *Client) {
+		c.connect.eager = false
+	}
+}
+
+// WithEagerConnect заставляет NewClient установить соединение и дождаться состояния Ready.
+// Если соединение не готово за timeout, NewClient возвращает ошибку.
+func WithEagerConnect(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.connect.eager = true
+		c.connect.timeout = timeout
+	}
+}
+
+// WithHealthCheck заставляет NewClient проверить сервис через grpc.health.v1 перед возвратом.
+// Пустое имя сервиса проверяет состояние сервера целиком.
+func WithHealthCheck(service string) ClientOption {
+	return func(c *Client) {
+		c.connect.healthCheck = true
+		c.connect.healthService = service
+	}
+}
+
+// connectOnCreate выполняет установку соединения и проверку здоровья согласно настройкам
+func (c *Client) connectOnCreate() error {
+	if !c.connect.eager && !c.connect.healthCheck {
+		return nil
+	}
+
+	timeout := c.connect.timeout
+	if timeout <= 0 {
+		timeout = c.timeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := c.WaitReady(ctx); err != nil {
+		return err
+	}
+	if c.connect.healthCheck {
+		return c.CheckHealth(ctx, c.connect.healthService)
+	}
+	return nil
+}
+
+// WaitReady инициирует соединение и ждет состояния Ready или отмены контекста
+func (c *Client) WaitReady(ctx context.Context) error {
+	if c.conn == nil {
+		return nil
+	}
+
+	c.conn.Connect()
+	for {
+		state := c.conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return fmt.Errorf("соединение закрыто")
+		case connectivity.Idle:
+			c.conn.Connect()
+		}
+
+		if !c.conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("соединение не готово, текущее состояние: %v: %w", c.conn.GetState(), ctx.Err())
+		}
+	}
+}
+
+// CheckHealth проверяет состояние сервиса через grpc.health.v1
+func (c *Client) CheckHealth(ctx context.Context, service string) error {
+	if c.conn == nil {
+		return nil
+	}
+
+	resp, err := healthpb.NewHealthClient(c.conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return fmt.Errorf("ошибка проверки здоровья: %w", wrapError(err))
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("сервис %q не готов: %s", service, resp.GetStatus())
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package grpcclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// authorizationHeader — ключ метаданных с токеном авторизации
+const authorizationHeader = "authorization"
+
+// CredentialsProvider поставляет токен авторизации для исходящих вызовов
+type CredentialsProvider interface {
+	// Token возвращает действующий токен, при необходимости получая новый
+	Token(ctx context.Context) (string, error)
+	// Invalidate сбрасывает закешированный токен; вызывается после ответа Unauthenticated
+	Invalidate()
+}
+
+// WithCredentials добавляет к каждому вызову и потоку заголовок "authorization: Bearer <token>".
+// Токен передается только по защищенному соединению: без WithTLS или WithMTLS NewClient
+// возвращает ошибку. При ответе Unauthenticated на унарный вызов токен сбрасывается
+// и вызов повторяется один раз.
+func WithCredentials(provider CredentialsProvider) ClientOption {
+	return func(c *Client) {
+		c.credentials = provider
+	}
+}
+
+// perRPCCredentials передает токен провайдера в метаданных каждого вызова и потока
+type perRPCCredentials struct {
+	provider CredentialsProvider
+}
+
+func (p perRPCCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	token, err := p.provider.Token(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "ошибка получения токена: %v", err)
+	}
+	return map[string]string{authorizationHeader: "Bearer " + token}, nil
+}
+
+// RequireTransportSecurity запрещает отправку токена по незащищенному соединению
+func (p perRPCCredentials) RequireTransportSecurity() bool {
+	return true
+}
+
+// StaticToken возвращает провайдер с постоянным токеном
+func StaticToken(token string) CredentialsProvider {
+	return staticToken(token)
+}
+
+type staticToken string
+
+func (t staticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+func (t staticToken) Invalidate() {}
+
+// defaultTokenLifetime — время жизни токена, для которого сервер не сообщил срок действия
+const defaultTokenLifetime = time.Hour
+
+// tokenFetchTimeout ограничивает получение токена, общее для всех ожидающих вызовов
+const tokenFetchTimeout = 30 * time.Second
+
+// tokenCache хранит токен до истечения срока действия за вычетом refreshBefore.
+// Токен получается вне блокировки, одновременные запросы объединяются в один.
+// После момента обновления вызовы получают прежний токен, пока новый запрашивается в фоне.
+type tokenCache struct {
+	fetch         func(ctx context.Context) (string, time.Time, error)
+	refreshBefore time.Duration
+	group         singleflight.Group
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+	expiry    time.Time
+}
+
+func (c *tokenCache) Token(ctx context.Context) (string, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	token, refreshAt, expiry := c.token, c.refreshAt, c.expiry
+	c.mu.Unlock()
+
+	if token != "" && now.Before(expiry) {
+		if !now.Before(refreshAt) {
+			c.group.DoChan("token", c.load(ctx))
+		}
+		return token, nil
+	}
+
+	select {
+	case res := <-c.group.DoChan("token", c.load(ctx)):
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(string), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// load возвращает функцию получения и сохранения токена. Получение не зависит
+// от отмены контекста вызывающего: его результат ждут и другие вызовы.
+func (c *tokenCache) load(ctx context.Context) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tokenFetchTimeout)
+		defer cancel()
+
+		token, expiry, err := c.fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.store(token, expiry, time.Now())
+		return token, nil
+	}
+}
+
+// store сохраняет токен. Обновление начинается за refreshBefore до истечения,
+// но не раньше середины срока действия, чтобы короткоживущий токен не запрашивался на каждый вызов.
+func (c *tokenCache) store(token string, expiry, now time.Time) {
+	if expiry.IsZero() {
+		expiry = now.Add(defaultTokenLifetime)
+	}
+	refreshBefore := min(c.refreshBefore, expiry.Sub(now)/2)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token, c.expiry, c.refreshAt = token, expiry, expiry.Add(-refreshBefore)
+}
+
+func (c *tokenCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = ""
+}
@@ -0,0 +1,236 @@
+package grpcclient
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tokenServer — token endpoint OAuth2, выдающий токены token-1, token-2, ...
+type tokenServer struct {
+	*httptest.Server
+	requests  atomic.Int64
+	expiresIn int64
+	delay     time.Duration
+}
+
+func newTokenServer(t *testing.T, expiresIn int64) *tokenServer {
+	t.Helper()
+	s := &tokenServer{expiresIn: expiresIn}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client" || secret != "secret" {
+			http.Error(w, "invalid client", http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("scope") != "read write" {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		time.Sleep(s.delay)
+
+		n := s.requests.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "token-" + string(rune('0'+n)),
+			"token_type":   "Bearer",
+			"expires_in":   s.expiresIn,
+		})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *tokenServer) provider() CredentialsProvider {
+	return NewOAuth2ClientCredentials(OAuth2Config{
+		TokenURL:     s.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+	})
+}
+
+func TestOAuth2TokenCached(t *testing.T) {
+	server := newTokenServer(t, 3600)
+	provider := server.provider()
+
+	for i := 0; i < 3; i++ {
+		token, err := provider.Token(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "token-1" {
+			t.Fatalf("токен %q, ожидался token-1", token)
+		}
+	}
+	if n := server.requests.Load(); n != 1 {
+		t.Fatalf("запросов к token endpoint: %d, ожидался 1", n)
+	}
+
+	provider.Invalidate()
+	if token, _ := provider.Token(context.Background()); token != "token-2" {
+		t.Fatalf("после Invalidate токен %q, ожидался token-2", token)
+	}
+}
+
+func TestOAuth2ConcurrentCallersShareFetch(t *testing.T) {
+	server := newTokenServer(t, 3600)
+	server.delay = 50 * time.Millisecond
+	provider := server.provider()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := provider.Token(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := server.requests.Load(); n != 1 {
+		t.Fatalf("запросов к token endpoint: %d, ожидался 1", n)
+	}
+}
+
+func TestOAuth2ShortLivedTokenNotRefetchedEveryCall(t *testing.T) {
+	// expires_in меньше RefreshBefore по умолчанию (1 минута)
+	server := newTokenServer(t, 30)
+	provider := server.provider()
+
+	for i := 0; i < 5; i++ {
+		if _, err := provider.Token(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := server.requests.Load(); n != 1 {
+		t.Fatalf("запросов к token endpoint: %d, ожидался 1", n)
+	}
+}
+
+func TestOAuth2TokenWithoutExpiry(t *testing.T) {
+	server := newTokenServer(t, 0)
+	provider := server.provider()
+
+	if _, err := provider.Token(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	cache := provider.(*tokenCache)
+	cache.mu.Lock()
+	expiry := cache.expiry
+	cache.mu.Unlock()
+	if lifetime := time.Until(expiry); lifetime <= 0 || lifetime > defaultTokenLifetime {
+		t.Fatalf("срок действия токена без expires_in: %v, ожидалось не больше %v", lifetime, defaultTokenLifetime)
+	}
+}
+
+func TestOAuth2EndpointError(t *testing.T) {
+	server := newTokenServer(t, 3600)
+	provider := NewOAuth2ClientCredentials(OAuth2Config{TokenURL: server.URL, ClientID: "client", ClientSecret: "wrong"})
+	if _, err := provider.Token(context.Background()); err == nil {
+		t.Fatal("ожидалась ошибка token endpoint")
+	}
+}
+
+func TestCredentialsRequireTLS(t *testing.T) {
+	client, err := NewClient("localhost:0", WithCredentials(StaticToken("secret")))
+	if err == nil {
+		client.Close()
+		t.Fatal("ожидалась ошибка: токен без TLS")
+	}
+}
+
+func TestCredentialsSentOverTLS(t *testing.T) {
+	ca := newTestCA(t)
+	tokens := make(chan string, 16)
+	capture := func(ctx context.Context) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		tokens <- md.Get(authorizationHeader)[0]
+	}
+
+	srv := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(ca.serverTLS(t, nil))),
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			capture(ctx)
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			capture(ss.Context())
+			return handler(srv, ss)
+		}),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writeFile(t, caFile, ca.pem)
+	client, err := NewClient(lis.Addr().String(), WithTLS(caFile), WithCredentials(newTokenServer(t, 3600).provider()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := Invoke[healthpb.HealthCheckResponse](ctx, client, healthpb.Health_Check_FullMethodName, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if token := <-tokens; token != "Bearer token-1" {
+		t.Fatalf("унарный вызов передал %q", token)
+	}
+
+	stream, err := client.OpenServerStream(ctx, healthpb.Health_Watch_FullMethodName, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	if _, err := Recv[healthpb.HealthCheckResponse](stream); err != nil {
+		t.Fatal(err)
+	}
+	if token := <-tokens; token != "Bearer token-1" {
+		t.Fatalf("поток передал %q", token)
+	}
+}
+
+func TestCredentialsRetryOnUnauthenticated(t *testing.T) {
+	const method = "/pkg.Service/Get"
+	var invalidated atomic.Int64
+	client := NewMockClient(WithCredentials(invalidateCounter{&invalidated}))
+	client.AddScript(method,
+		MockStep{Err: status.Error(codes.Unauthenticated, "токен истек")},
+		MockStep{Err: nil},
+	)
+
+	if err := client.invoke(context.Background(), method, &healthpb.HealthCheckRequest{}, &healthpb.HealthCheckResponse{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if invalidated.Load() != 1 {
+		t.Fatalf("Invalidate вызван %d раз, ожидался 1", invalidated.Load())
+	}
+}
+
+type invalidateCounter struct{ n *atomic.Int64 }
+
+func (c invalidateCounter) Token(context.Context) (string, error) { return "token", nil }
+func (c invalidateCounter) Invalidate()                           { c.n.Add(1) }
@@ -0,0 +1,243 @@
+package grpcclient
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// DescriptorSource поставляет описания сервисов для динамических вызовов
+type DescriptorSource interface {
+	// ListServices возвращает полные имена сервисов
+	ListServices(ctx context.Context) ([]string, error)
+	// FindSymbol возвращает описание сервиса, метода или сообщения по полному имени
+	FindSymbol(ctx context.Context, name string) (protoreflect.Descriptor, error)
+	// Types возвращает реестр типов, известных источнику (для google.protobuf.Any в JSON)
+	Types() *dynamicpb.Types
+}
+
+// NewDescriptorSetSource загружает описания из файла FileDescriptorSet,
+// созданного protoc --descriptor_set_out --include_imports
+func NewDescriptorSetSource(path string) (DescriptorSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения набора дескрипторов: %w", err)
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("ошибка разбора набора дескрипторов: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка построения дескрипторов: %w", err)
+	}
+	return &fileSource{files: files, types: dynamicpb.NewTypes(files)}, nil
+}
+
+// fileSource — источник описаний из набора файлов
+type fileSource struct {
+	files *protoregistry.Files
+	types *dynamicpb.Types
+}
+
+func (s *fileSource) ListServices(context.Context) ([]string, error) {
+	var services []string
+	s.files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		for i := 0; i < fd.Services().Len(); i++ {
+			services = append(services, string(fd.Services().Get(i).FullName()))
+		}
+		return true
+	})
+	return services, nil
+}
+
+func (s *fileSource) FindSymbol(_ context.Context, name string) (protoreflect.Descriptor, error) {
+	d, err := s.files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("символ %s не найден: %w", name, err)
+	}
+	return d, nil
+}
+
+func (s *fileSource) Types() *dynamicpb.Types {
+	return s.types
+}
+
+// NewReflectionSource получает описания у сервера через grpc.reflection.v1
+// (grpcserver регистрирует этот сервис автоматически)
+func NewReflectionSource(c *Client) DescriptorSource {
+	return &reflectionSource{
+		client: reflectionpb.NewServerReflectionClient(c),
+		protos: make(map[string]*descriptorpb.FileDescriptorProto),
+		files:  new(protoregistry.Files),
+	}
+}
+
+// reflectionSource — источник описаний, запрашивающий их у сервера
+type reflectionSource struct {
+	client reflectionpb.ServerReflectionClient
+
+	mu     sync.Mutex
+	protos map[string]*descriptorpb.FileDescriptorProto
+	files  *protoregistry.Files
+}
+
+func (s *reflectionSource) ListServices(ctx context.Context) ([]string, error) {
+	resp, err := s.request(ctx, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	return services, nil
+}
+
+func (s *reflectionSource) FindSymbol(ctx context.Context, name string) (protoreflect.Descriptor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if d, err := s.files.FindDescriptorByName(protoreflect.FullName(name)); err == nil {
+		return d, nil
+	}
+
+	// Не все серверы находят файл по имени метода: тогда ищем по имени сервиса
+	if err := s.fetchSymbol(ctx, name); err != nil {
+		i := strings.LastIndex(name, ".")
+		if i <= 0 {
+			return nil, err
+		}
+		if err := s.fetchSymbol(ctx, name[:i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.rebuild(ctx); err != nil {
+		return nil, err
+	}
+	d, err := s.files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("символ %s не найден: %w", name, err)
+	}
+	return d, nil
+}
+
+func (s *reflectionSource) Types() *dynamicpb.Types {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return dynamicpb.NewTypes(s.files)
+}
+
+func (s *reflectionSource) fetchSymbol(ctx context.Context, symbol string) error {
+	return s.fetch(ctx, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	})
+}
+
+func (s *reflectionSource) fetchFile(ctx context.Context, filename string) error {
+	return s.fetch(ctx, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: filename},
+	})
+}
+
+// fetch запрашивает файлы у сервера и сохраняет их
+func (s *reflectionSource) fetch(ctx context.Context, msg *reflectionpb.ServerReflectionRequest) error {
+	resp, err := s.request(ctx, msg)
+	if err != nil {
+		return err
+	}
+	if errResp := resp.GetErrorResponse(); errResp != nil {
+		return fmt.Errorf("ошибка reflection: %s", errResp.GetErrorMessage())
+	}
+
+	for _, raw := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		fd := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(raw, fd); err != nil {
+			return fmt.Errorf("ошибка разбора дескриптора: %w", err)
+		}
+		s.protos[fd.GetName()] = fd
+	}
+	return nil
+}
+
+// rebuild догружает недостающие зависимости и пересобирает реестр файлов
+func (s *reflectionSource) rebuild(ctx context.Context) error {
+	for {
+		var missing []string
+		for _, fd := range s.protos {
+			for _, dep := range fd.GetDependency() {
+				if _, ok := s.protos[dep]; !ok {
+					missing = append(missing, dep)
+				}
+			}
+		}
+		if len(missing) == 0 {
+			break
+		}
+		for _, name := range missing {
+			if _, ok := s.protos[name]; ok {
+				continue
+			}
+			if err := s.fetchFile(ctx, name); err != nil {
+				return err
+			}
+			if _, ok := s.protos[name]; !ok {
+				return fmt.Errorf("сервер не вернул файл %s", name)
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range s.protos {
+		set.File = append(set.File, fd)
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return fmt.Errorf("ошибка построения дескрипторов: %w", err)
+	}
+	s.files = files
+	return nil
+}
+
+// request выполняет один запрос в потоке reflection
+func (s *reflectionSource) request(ctx context.Context, req *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := s.client.ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(req); err != nil {
+		return nil, wrapError(err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	// Дочитываем поток до конца: без EOF поток Client не завершает span и не освобождает ресурсы
+	for {
+		if _, err := stream.Recv(); err != nil {
+			break
+		}
+	}
+	return resp, nil
+}
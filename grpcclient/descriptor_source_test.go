@@ -0,0 +1,70 @@
+package grpcclient
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+func startReflectionServer(t *testing.T) string {
+	t.Helper()
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	reflection.Register(srv)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+func TestReflectionSourceEndsStreams(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer provider.Shutdown(context.Background())
+
+	client, err := NewClient(startReflectionServer(t), WithTracing(TracingConfig{TracerProvider: provider}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	source := NewReflectionSource(client)
+	services, err := source.ListServices(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(services, "grpc.health.v1.Health") {
+		t.Fatalf("сервисы %v не содержат grpc.health.v1.Health", services)
+	}
+	if _, err := source.FindSymbol(ctx, "grpc.health.v1.Health"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Каждый запрос reflection открывает отдельный поток; все их спаны должны быть завершены
+	spans := exporter.GetSpans()
+	if len(spans) < 2 {
+		t.Fatalf("завершено %d спанов, ожидалось не меньше 2", len(spans))
+	}
+	for _, span := range spans {
+		if span.Status.Code == otelcodes.Error {
+			t.Errorf("спан %s завершен с ошибкой: %s", span.Name, span.Status.Description)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// DynamicClient выполняет вызовы по имени метода с JSON телом,
+// без сгенерированного кода. Типы сообщений берутся из DescriptorSource.
+// Вызовы проходят через Client, поэтому для них действуют таймауты,
+// повторы, метаданные и учетные данные клиента.
+type DynamicClient struct {
+	client *Client
+	source DescriptorSource
+}
+
+// NewDynamicClient создает динамический клиент:
+//
+//	dyn := grpcclient.NewDynamicClient(client, grpcclient.NewReflectionSource(client))
+//	out, err := dyn.InvokeJSON(ctx, "message_service.users.v1.UsersService/GetStatusInfo", []byte(`{}`))
+func NewDynamicClient(c *Client, source DescriptorSource) *DynamicClient {
+	return &DynamicClient{client: c, source: source}
+}
+
+// Source возвращает источник описаний клиента
+func (d *DynamicClient) Source() DescriptorSource {
+	return d.source
+}
+
+// FindMethod находит описание метода. Имя принимается в виде
+// "pkg.Service/Method", "/pkg.Service/Method" или "pkg.Service.Method".
+func (d *DynamicClient) FindMethod(ctx context.Context, method string) (protoreflect.MethodDescriptor, error) {
+	name := strings.ReplaceAll(strings.TrimPrefix(method, "/"), "/", ".")
+	desc, err := d.source.FindSymbol(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	md, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s не является методом", name)
+	}
+	return md, nil
+}
+
+// InvokeJSON выполняет унарный вызов: input — запрос в JSON, результат — ответ в JSON
+func (d *DynamicClient) InvokeJSON(ctx context.Context, method string, input []byte, opts ...grpc.CallOption) ([]byte, error) {
+	md, err := d.FindMethod(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, fmt.Errorf("метод %s потоковый, используйте ServerStreamJSON", md.FullName())
+	}
+
+	req, err := d.unmarshal(md.Input(), input)
+	if err != nil {
+		return nil, err
+	}
+	resp := dynamicpb.NewMessage(md.Output())
+	if err := d.client.Invoke(ctx, fullMethodName(md), req, resp, opts...); err != nil {
+		return nil, err
+	}
+	return d.marshal(resp)
+}
+
+// ServerStreamJSON выполняет вызов серверного потока и передает каждое сообщение
+// в JSON в onMessage. Ошибка onMessage прерывает поток и возвращается вызывающему.
+func (d *DynamicClient) ServerStreamJSON(ctx context.Context, method string, input []byte, onMessage func([]byte) error, opts ...StreamOption) error {
+	md, err := d.FindMethod(ctx, method)
+	if err != nil {
+		return err
+	}
+	if md.IsStreamingClient() || !md.IsStreamingServer() {
+		return fmt.Errorf("метод %s не является серверным потоком", md.FullName())
+	}
+
+	req, err := d.unmarshal(md.Input(), input)
+	if err != nil {
+		return err
+	}
+	stream, err := d.client.OpenServerStream(ctx, fullMethodName(md), req, opts...)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	for {
+		resp := dynamicpb.NewMessage(md.Output())
+		if err := stream.RecvMsg(resp); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		out, err := d.marshal(resp)
+		if err != nil {
+			return err
+		}
+		if err := onMessage(out); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *DynamicClient) unmarshal(desc protoreflect.MessageDescriptor, input []byte) (*dynamicpb.Message, error) {
+	msg := dynamicpb.NewMessage(desc)
+	if len(strings.TrimSpace(string(input))) == 0 {
+		return msg, nil
+	}
+	opts := protojson.UnmarshalOptions{Resolver: d.source.Types()}
+	if err := opts.Unmarshal(input, msg); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON запроса %s: %w", desc.FullName(), err)
+	}
+	return msg, nil
+}
+
+func (d *DynamicClient) marshal(msg *dynamicpb.Message) ([]byte, error) {
+	opts := protojson.MarshalOptions{Resolver: d.source.Types()}
+	out, err := opts.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка формирования JSON ответа %s: %w", msg.Descriptor().FullName(), err)
+	}
+	return out, nil
+}
+
+// fullMethodName возвращает имя метода в формате gRPC: /pkg.Service/Method
+func fullMethodName(md protoreflect.MethodDescriptor) string {
+	return fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name())
+}
@@ -0,0 +1,157 @@
+package grpcclient
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Error — ошибка вызова с кодом gRPC статуса и разобранными деталями google.rpc
+type Error struct {
+	Code    codes.Code
+	Message string
+
+	// Детали статуса, если сервер их передал
+	BadRequest   *errdetails.BadRequest
+	RetryInfo    *errdetails.RetryInfo
+	ErrorInfo    *errdetails.ErrorInfo
+	QuotaFailure *errdetails.QuotaFailure
+	// Details — все детали статуса, включая неразобранные выше
+	Details []proto.Message
+
+	err    error
+	status *status.Status
+}
+
+// Сигнальные ошибки для errors.Is, совпадающие с любым *Error с тем же кодом:
+//
+//	if errors.Is(err, grpcclient.ErrNotFound) { ... }
+var (
+	ErrCanceled           = &Error{Code: codes.Canceled}
+	ErrInvalidArgument    = &Error{Code: codes.InvalidArgument}
+	ErrDeadlineExceeded   = &Error{Code: codes.DeadlineExceeded}
+	ErrNotFound           = &Error{Code: codes.NotFound}
+	ErrAlreadyExists      = &Error{Code: codes.AlreadyExists}
+	ErrPermissionDenied   = &Error{Code: codes.PermissionDenied}
+	ErrResourceExhausted  = &Error{Code: codes.ResourceExhausted}
+	ErrFailedPrecondition = &Error{Code: codes.FailedPrecondition}
+	ErrAborted            = &Error{Code: codes.Aborted}
+	ErrUnimplemented      = &Error{Code: codes.Unimplemented}
+	ErrInternal           = &Error{Code: codes.Internal}
+	ErrUnavailable        = &Error{Code: codes.Unavailable}
+	ErrUnauthenticated    = &Error{Code: codes.Unauthenticated}
+)
+
+func (e *Error) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("ошибка gRPC [%s]", e.Code)
+	}
+	if e.status == nil {
+		return fmt.Sprintf("неизвестная ошибка: %v", e.err)
+	}
+	return fmt.Sprintf("ошибка gRPC [%s]: %v", e.Code, e.err)
+}
+
+// Unwrap возвращает исходную ошибку вызова
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Is сравнивает ошибку с сигнальными ошибками по коду
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.err == nil && t.Code == e.Code
+}
+
+// GRPCStatus возвращает исходный статус со всеми деталями
+func (e *Error) GRPCStatus() *status.Status {
+	if e.status != nil {
+		return e.status
+	}
+	return status.New(e.Code, e.Message)
+}
+
+// wrapError преобразует ошибку вызова в *Error, разбирая детали статуса
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var wrapped *Error
+	if errors.As(err, &wrapped) {
+		return err
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return &Error{Code: codes.Unknown, Message: err.Error(), err: err}
+	}
+
+	e := &Error{Code: st.Code(), Message: st.Message(), err: err, status: st}
+	for _, detail := range st.Details() {
+		msg, ok := detail.(proto.Message)
+		if !ok {
+			continue
+		}
+		e.Details = append(e.Details, msg)
+
+		switch d := msg.(type) {
+		case *errdetails.BadRequest:
+			e.BadRequest = d
+		case *errdetails.RetryInfo:
+			e.RetryInfo = d
+		case *errdetails.ErrorInfo:
+			e.ErrorInfo = d
+		case *errdetails.QuotaFailure:
+			e.QuotaFailure = d
+		}
+	}
+	return e
+}
+
+// AsError извлекает *Error из цепочки ошибок
+func AsError(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// Code возвращает код gRPC статуса ошибки; для nil — codes.OK
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if e, ok := AsError(err); ok {
+		return e.Code
+	}
+	return status.Code(err)
+}
+
+// IsNotFound сообщает, завершился ли вызов с кодом NotFound
+func IsNotFound(err error) bool { return Code(err) == codes.NotFound }
+
+// IsUnavailable сообщает, завершился ли вызов с кодом Unavailable
+func IsUnavailable(err error) bool { return Code(err) == codes.Unavailable }
+
+// IsDeadlineExceeded сообщает, завершился ли вызов с кодом DeadlineExceeded
+func IsDeadlineExceeded(err error) bool { return Code(err) == codes.DeadlineExceeded }
+
+// IsInvalidArgument сообщает, завершился ли вызов с кодом InvalidArgument
+func IsInvalidArgument(err error) bool { return Code(err) == codes.InvalidArgument }
+
+// IsAlreadyExists сообщает, завершился ли вызов с кодом AlreadyExists
+func IsAlreadyExists(err error) bool { return Code(err) == codes.AlreadyExists }
+
+// IsPermissionDenied сообщает, завершился ли вызов с кодом PermissionDenied
+func IsPermissionDenied(err error) bool { return Code(err) == codes.PermissionDenied }
+
+// IsUnauthenticated сообщает, завершился ли вызов с кодом Unauthenticated
+func IsUnauthenticated(err error) bool { return Code(err) == codes.Unauthenticated }
+
+// IsResourceExhausted сообщает, завершился ли вызов с кодом ResourceExhausted
+func IsResourceExhausted(err error) bool { return Code(err) == codes.ResourceExhausted }
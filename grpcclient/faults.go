@@ -0,0 +1,364 @@
+package grpcclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ProdEnvMode — значение EnvMode продового окружения; в нем внедрение ошибок по умолчанию выключено
+const ProdEnvMode = "production"
+
+// FaultRule описывает ошибку, внедряемую в вызовы методов
+type FaultRule struct {
+	// Method — шаблон полного имени метода в синтаксисе path.Match:
+	// "/pkg.Service/Method", "/pkg.Service/*" или "*" для всех методов
+	Method string `json:"method"`
+	// Probability — вероятность срабатывания правила от 0 до 1
+	Probability float64 `json:"probability"`
+	// Delay — задержка перед вызовом; в JSON — строка вида "200ms"
+	Delay time.Duration `json:"-"`
+	// Code — код, с которым завершается вызов; OK — вызов выполняется после задержки
+	Code codes.Code `json:"code,omitempty"`
+	// Message — текст ошибки
+	Message string `json:"message,omitempty"`
+	// AbortAfter — для потоков: прервать поток после указанного числа полученных сообщений
+	// с кодом Code (Aborted, если Code не задан); 0 — не прерывать
+	AbortAfter int `json:"abortAfter,omitempty"`
+}
+
+// faultRuleJSON — FaultRule с задержкой в формате time.Duration.String
+type faultRuleJSON struct {
+	faultRuleFields
+	Delay string `json:"delay,omitempty"`
+}
+
+type faultRuleFields FaultRule
+
+func (r FaultRule) MarshalJSON() ([]byte, error) {
+	out := faultRuleJSON{faultRuleFields: faultRuleFields(r)}
+	if r.Delay > 0 {
+		out.Delay = r.Delay.String()
+	}
+	return json.Marshal(out)
+}
+
+func (r *FaultRule) UnmarshalJSON(data []byte) error {
+	var in faultRuleJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*r = FaultRule(in.faultRuleFields)
+	if in.Delay != "" {
+		delay, err := time.ParseDuration(in.Delay)
+		if err != nil {
+			return fmt.Errorf("delay: %w", err)
+		}
+		r.Delay = delay
+	}
+	return nil
+}
+
+// matches проверяет, относится ли правило к методу
+func (r FaultRule) matches(method string) bool {
+	if r.Method == "*" {
+		return true
+	}
+	ok, err := path.Match(normalizeMethod(r.Method), method)
+	return err == nil && ok
+}
+
+// FaultInjectorConfig описывает начальные настройки внедрения ошибок
+type FaultInjectorConfig struct {
+	Rules []FaultRule
+	// EnvMode — окружение сервиса; в ProdEnvMode внедрение выключено, пока не вызван Enable
+	EnvMode string
+}
+
+// FaultInjector внедряет задержки и ошибки в исходящие вызовы по правилам.
+// Правила можно менять во время работы через SetRules или HTTP обработчик.
+type FaultInjector struct {
+	mu      sync.RWMutex
+	rules   []FaultRule
+	enabled bool
+}
+
+// NewFaultInjector создает FaultInjector. В продовом окружении он создается выключенным.
+func NewFaultInjector(cfg FaultInjectorConfig) *FaultInjector {
+	return &FaultInjector{
+		rules:   append([]FaultRule(nil), cfg.Rules...),
+		enabled: cfg.EnvMode != ProdEnvMode,
+	}
+}
+
+// WithFaultInjection включает внедрение ошибок в унарные вызовы и потоки клиента.
+// Ошибки внедряются до обращения к сети, в том числе для клиентов из NewMockClient.
+// Правило выбирается один раз на унарный вызов: задержка и ошибка применяются до первой
+// попытки, внедренная ошибка не повторяется, не хеджируется и не учитывается выключателем.
+// Для потоков правило выбирается при каждом открытии, включая переоткрытие серверного потока.
+func WithFaultInjection(f *FaultInjector) ClientOption {
+	return func(c *Client) {
+		c.faults = f
+	}
+}
+
+// SetRules заменяет правила внедрения ошибок
+func (f *FaultInjector) SetRules(rules ...FaultRule) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append([]FaultRule(nil), rules...)
+}
+
+// Rules возвращает текущие правила
+func (f *FaultInjector) Rules() []FaultRule {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return append([]FaultRule(nil), f.rules...)
+}
+
+// Enable включает внедрение ошибок
+func (f *FaultInjector) Enable() {
+	f.setEnabled(true)
+}
+
+// Disable выключает внедрение ошибок, правила сохраняются
+func (f *FaultInjector) Disable() {
+	f.setEnabled(false)
+}
+
+// Enabled сообщает, включено ли внедрение ошибок
+func (f *FaultInjector) Enabled() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.enabled
+}
+
+func (f *FaultInjector) setEnabled(enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.enabled = enabled
+}
+
+// pick возвращает сработавшее для метода правило
+func (f *FaultInjector) pick(method string) (FaultRule, bool) {
+	if f == nil {
+		return FaultRule{}, false
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if !f.enabled {
+		return FaultRule{}, false
+	}
+	method = normalizeMethod(method)
+	for _, rule := range f.rules {
+		if rule.matches(method) && rand.Float64() < rule.Probability {
+			return rule, true
+		}
+	}
+	return FaultRule{}, false
+}
+
+// inject применяет задержку и ошибку правила, сработавшего для метода.
+// Возвращает правило, чтобы поток мог быть прерван позже.
+func (f *FaultInjector) inject(ctx context.Context, method string) (FaultRule, error) {
+	rule, ok := f.pick(method)
+	if !ok {
+		return FaultRule{}, nil
+	}
+	if err := sleepContext(ctx, rule.Delay); err != nil {
+		return rule, status.FromContextError(err).Err()
+	}
+	if rule.Code != codes.OK && rule.AbortAfter == 0 {
+		return rule, status.Error(rule.Code, rule.message(method))
+	}
+	return rule, nil
+}
+
+func (r FaultRule) message(method string) string {
+	if r.Message != "" {
+		return r.Message
+	}
+	return fmt.Sprintf("внедренная ошибка вызова %s", method)
+}
+
+// UnaryInterceptor возвращает интерцептор, внедряющий ошибки в унарные вызовы любого grpc.ClientConn.
+// Интерцептор соединения вызывается для каждой попытки, поэтому правило выбирается для каждой попытки.
+func (f *FaultInjector) UnaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, err := f.inject(ctx, method); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamInterceptor возвращает интерцептор, внедряющий ошибки в потоки любого grpc.ClientConn
+func (f *FaultInjector) StreamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		rule, err := f.inject(ctx, method)
+		if err != nil {
+			return nil, err
+		}
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return abortStream(stream, method, rule), nil
+	}
+}
+
+// abortStream оборачивает поток, если правило требует прервать его
+func abortStream(stream grpc.ClientStream, method string, rule FaultRule) grpc.ClientStream {
+	if rule.AbortAfter <= 0 {
+		return stream
+	}
+	code := rule.Code
+	if code == codes.OK {
+		code = codes.Aborted
+	}
+	return &faultStream{ClientStream: stream, left: rule.AbortAfter, err: status.Error(code, rule.message(method))}
+}
+
+// faultStream прерывает поток после заданного числа полученных сообщений
+type faultStream struct {
+	grpc.ClientStream
+	left int
+	err  error
+}
+
+func (s *faultStream) RecvMsg(m interface{}) error {
+	if s.left <= 0 {
+		return s.err
+	}
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.left--
+	return nil
+}
+
+// ServeHTTP — административный обработчик правил:
+// GET возвращает состояние, PUT заменяет правила и включает или выключает внедрение,
+// DELETE удаляет все правила.
+//
+//	{"enabled": true, "rules": [{"method": "/pkg.Service/*", "probability": 0.1, "code": 14}]}
+func (f *FaultInjector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	type state struct {
+		Enabled *bool       `json:"enabled,omitempty"`
+		Rules   []FaultRule `json:"rules"`
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req state
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.SetRules(req.Rules...)
+		if req.Enabled != nil {
+			f.setEnabled(*req.Enabled)
+		}
+	case http.MethodDelete:
+		f.SetRules()
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	enabled := f.Enabled()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(state{Enabled: &enabled, Rules: f.Rules()})
+}
+
+// ParseFaultRules разбирает правила из строки вида
+//
+//	/pkg.Service/Get*=probability:0.1,delay:200ms,code:Unavailable;*=probability:0.01,abort:3
+//
+// Параметры правила: probability (по умолчанию 1), delay, code (имя или число), message, abort.
+func ParseFaultRules(spec string) ([]FaultRule, error) {
+	var rules []FaultRule
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		method, params, _ := strings.Cut(part, "=")
+		rule := FaultRule{Method: strings.TrimSpace(method), Probability: 1}
+		if rule.Method == "" {
+			return nil, fmt.Errorf("правило %q: не указан метод", part)
+		}
+		if _, err := path.Match(rule.Method, ""); err != nil {
+			return nil, fmt.Errorf("правило %q: %w", part, err)
+		}
+
+		for _, param := range strings.Split(params, ",") {
+			if strings.TrimSpace(param) == "" {
+				continue
+			}
+			key, value, _ := strings.Cut(param, ":")
+			if err := rule.set(strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("правило %q: %w", part, err)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// set устанавливает параметр правила из строки
+func (r *FaultRule) set(key, value string) error {
+	var err error
+	switch key {
+	case "probability", "p":
+		r.Probability, err = strconv.ParseFloat(value, 64)
+	case "delay":
+		r.Delay, err = time.ParseDuration(value)
+	case "code":
+		r.Code, err = parseCode(value)
+	case "message":
+		r.Message = value
+	case "abort":
+		r.AbortAfter, err = strconv.Atoi(value)
+	default:
+		return fmt.Errorf("неизвестный параметр %q", key)
+	}
+	if err != nil {
+		return fmt.Errorf("параметр %s: %w", key, err)
+	}
+	return nil
+}
+
+// parseCode разбирает код gRPC по имени (Unavailable) или числу (14)
+func parseCode(value string) (codes.Code, error) {
+	if n, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return codes.Code(n), nil
+	}
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if strings.EqualFold(c.String(), value) {
+			return c, nil
+		}
+	}
+	return codes.OK, fmt.Errorf("неизвестный код %q", value)
+}
+
+// FaultInjector возвращает FaultInjector клиента (nil, если внедрение не настроено),
+// например чтобы подключить его ServeHTTP к административному серверу
+func (c *Client) FaultInjector() *FaultInjector {
+	return c.faults
+}
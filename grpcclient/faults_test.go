@@ -0,0 +1,160 @@
+package grpcclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestParseFaultRules(t *testing.T) {
+	rules, err := ParseFaultRules("/pkg.Service/Get*=probability:0.1,delay:200ms,code:Unavailable; *=p:0.01,abort:3,code:10 ;/pkg.Service/Put")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FaultRule{
+		{Method: "/pkg.Service/Get*", Probability: 0.1, Delay: 200 * time.Millisecond, Code: codes.Unavailable},
+		{Method: "*", Probability: 0.01, AbortAfter: 3, Code: codes.Aborted},
+		{Method: "/pkg.Service/Put", Probability: 1},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("разобрано %d правил, ожидалось %d", len(rules), len(want))
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("правило %d: %+v, ожидалось %+v", i, rules[i], want[i])
+		}
+	}
+
+	for _, spec := range []string{
+		"=code:Unavailable",
+		"/pkg.Service/[=p:1",
+		"*=probability:x",
+		"*=code:Unknown1",
+		"*=delay:1",
+		"*=retries:3",
+	} {
+		if _, err := ParseFaultRules(spec); err == nil {
+			t.Errorf("ParseFaultRules(%q): ожидалась ошибка", spec)
+		}
+	}
+}
+
+func TestFaultInjectedOncePerCall(t *testing.T) {
+	const method = "/pkg.Service/Get"
+	injector := NewFaultInjector(FaultInjectorConfig{Rules: []FaultRule{
+		{Method: "/pkg.Service/*", Probability: 1, Code: codes.Unavailable},
+	}})
+	client := NewMockClient(WithFaultInjection(injector), WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+	}))
+	client.AddScript(method, MockStep{Response: wrapperspb.String("ok")})
+
+	err := client.Invoke(context.Background(), method, wrapperspb.String("req"), &wrapperspb.StringValue{})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("код %s, ожидался Unavailable", status.Code(err))
+	}
+	// Внедренная ошибка не повторяется и не доходит до обработчика
+	client.AssertNotCalled(t, method)
+
+	injector.Disable()
+	if err := client.Invoke(context.Background(), method, wrapperspb.String("req"), &wrapperspb.StringValue{}); err != nil {
+		t.Fatal(err)
+	}
+	client.AssertNumberOfCalls(t, method, 1)
+
+	// Задержка правила добавляется к вызову один раз, а не к каждой из трех попыток
+	const delay = 100 * time.Millisecond
+	injector.SetRules(FaultRule{Method: method, Probability: 1, Delay: delay})
+	injector.Enable()
+	client.AddScript(method,
+		MockStep{Err: status.Error(codes.Unavailable, "недоступен")},
+		MockStep{Err: status.Error(codes.Unavailable, "недоступен")},
+		MockStep{Response: wrapperspb.String("ok")},
+	)
+	start := time.Now()
+	if err := client.Invoke(context.Background(), method, wrapperspb.String("req"), &wrapperspb.StringValue{}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < delay || elapsed >= 2*delay {
+		t.Fatalf("вызов длился %v, ожидалась одна задержка %v", elapsed, delay)
+	}
+	client.AssertNumberOfCalls(t, method, 4)
+}
+
+func TestFaultInjectorDisabledInProduction(t *testing.T) {
+	injector := NewFaultInjector(FaultInjectorConfig{
+		Rules:   []FaultRule{{Method: "*", Probability: 1, Code: codes.Internal}},
+		EnvMode: ProdEnvMode,
+	})
+	if injector.Enabled() {
+		t.Fatal("в продовом окружении внедрение должно быть выключено")
+	}
+	if _, err := injector.inject(context.Background(), "/pkg.Service/Get"); err != nil {
+		t.Fatalf("выключенный injector вернул ошибку: %v", err)
+	}
+}
+
+func TestFaultInjectorServeHTTP(t *testing.T) {
+	injector := NewFaultInjector(FaultInjectorConfig{EnvMode: ProdEnvMode})
+
+	type state struct {
+		Enabled bool        `json:"enabled"`
+		Rules   []FaultRule `json:"rules"`
+	}
+	do := func(method, body string) (int, state) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		injector.ServeHTTP(rec, httptest.NewRequest(method, "/faults", strings.NewReader(body)))
+		var got state
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, got
+	}
+
+	if code, got := do(http.MethodGet, ""); code != http.StatusOK || got.Enabled || len(got.Rules) != 0 {
+		t.Fatalf("GET: %d %+v", code, got)
+	}
+
+	code, got := do(http.MethodPut, `{"enabled": true, "rules": [{"method": "/pkg.Service/*", "probability": 0.5, "delay": "200ms", "code": 14}]}`)
+	if code != http.StatusOK || !got.Enabled || len(got.Rules) != 1 {
+		t.Fatalf("PUT: %d %+v", code, got)
+	}
+	want := FaultRule{Method: "/pkg.Service/*", Probability: 0.5, Delay: 200 * time.Millisecond, Code: codes.Unavailable}
+	if got.Rules[0] != want || injector.Rules()[0] != want {
+		t.Fatalf("правило %+v, ожидалось %+v", got.Rules[0], want)
+	}
+	if !injector.Enabled() {
+		t.Fatal("PUT с enabled: true не включил внедрение")
+	}
+
+	// Без поля enabled состояние не меняется
+	if code, got := do(http.MethodPost, `{"rules": []}`); code != http.StatusOK || !got.Enabled || len(got.Rules) != 0 {
+		t.Fatalf("POST: %d %+v", code, got)
+	}
+
+	injector.SetRules(want)
+	if code, got := do(http.MethodDelete, ""); code != http.StatusOK || len(got.Rules) != 0 || len(injector.Rules()) != 0 {
+		t.Fatalf("DELETE: %d %+v", code, got)
+	}
+
+	if code, _ := do(http.MethodPut, `{"rules": [{"delay": "soon"}]}`); code != http.StatusBadRequest {
+		t.Fatalf("PUT с некорректной задержкой: %d, ожидался 400", code)
+	}
+	if code, _ := do(http.MethodPatch, ""); code != http.StatusMethodNotAllowed {
+		t.Fatalf("PATCH: %d, ожидался 405", code)
+	}
+}
@@ -1,4 +1,33 @@
 package grpcclient_config
 
+import "time"
+
 type GrpcclientConfig struct {
+	Target string `envconfig:"TARGET" required:"true"`
+	// Timeout и MaxRetries применяются, только если больше нуля; иначе действуют значения клиента по умолчанию
+	Timeout        time.Duration `envconfig:"TIMEOUT" default:"5s"`
+	MaxRetries     int           `envconfig:"MAX_RETRIES" default:"1"`
+	ConnectTimeout time.Duration `envconfig:"CONNECT_TIMEOUT" default:"0s"`
+
+	TLSEnabled        bool          `envconfig:"TLS_ENABLED" default:"false"`
+	TLSCAFile         string        `envconfig:"TLS_CA_FILE"`
+	TLSCertFile       string        `envconfig:"TLS_CERT_FILE"`
+	TLSKeyFile        string        `envconfig:"TLS_KEY_FILE"`
+	TLSServerName     string        `envconfig:"TLS_SERVER_NAME"`
+	TLSReloadInterval time.Duration `envconfig:"TLS_RELOAD_INTERVAL" default:"0s"`
+
+	KeepaliveTime                time.Duration `envconfig:"KEEPALIVE_TIME" default:"0s"`
+	KeepaliveTimeout             time.Duration `envconfig:"KEEPALIVE_TIMEOUT" default:"20s"`
+	KeepalivePermitWithoutStream bool          `envconfig:"KEEPALIVE_PERMIT_WITHOUT_STREAM" default:"false"`
+
+	Compression    string `envconfig:"COMPRESSION"`
+	MaxRecvMsgSize int    `envconfig:"MAX_RECV_MSG_SIZE" default:"4194304"`
+	MaxSendMsgSize int    `envconfig:"MAX_SEND_MSG_SIZE" default:"2147483647"`
+
+	Metadata            map[string]string `envconfig:"METADATA"`
+	LoadBalancingPolicy string            `envconfig:"LB_POLICY" default:"pick_first"`
+	Addresses           []string          `envconfig:"ADDRESSES"`
+
+	EnvMode        string `envconfig:"ENV_MODE" default:"dev"`
+	FaultInjection string `envconfig:"FAULT_INJECTION"`
 }
@@ -0,0 +1,288 @@
+package grpcclient
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// minLatencySamples — число замеров, после которого используется p95 задержки метода
+const minLatencySamples = 20
+
+// latencyWindowSize — число последних замеров, по которым считается p95
+const latencyWindowSize = 100
+
+// idempotentOption помечает вызов как идемпотентный
+type idempotentOption struct {
+	grpc.EmptyCallOption
+}
+
+// Idempotent помечает вызов как идемпотентный, разрешая для него хеджирование:
+//
+//	users.GetStatusInfo(ctx, req, grpcclient.Idempotent())
+func Idempotent() grpc.CallOption {
+	return idempotentOption{}
+}
+
+// WithIdempotentMethods помечает методы клиента как идемпотентные
+func WithIdempotentMethods(methods ...string) ClientOption {
+	return func(c *Client) {
+		for _, method := range methods {
+			c.idempotentMethods[normalizeMethod(method)] = struct{}{}
+		}
+	}
+}
+
+// isIdempotent проверяет, помечен ли вызов как идемпотентный
+func (c *Client) isIdempotent(method string, opts []grpc.CallOption) bool {
+	if _, ok := c.idempotentMethods[normalizeMethod(method)]; ok {
+		return true
+	}
+	return slices.ContainsFunc(opts, func(opt grpc.CallOption) bool {
+		_, ok := opt.(idempotentOption)
+		return ok
+	})
+}
+
+// HedgingConfig описывает параметры хеджирования запросов
+type HedgingConfig struct {
+	// Delay — задержка перед отправкой дополнительной попытки
+	Delay time.Duration
+	// AdaptiveDelay включает задержку, равную p95 задержки метода;
+	// пока замеров недостаточно, используется Delay
+	AdaptiveDelay bool
+	// MaxHedges — число дополнительных попыток на вызов; по умолчанию 1
+	MaxHedges int
+	// MaxConcurrentHedges ограничивает число одновременных дополнительных попыток клиента; 0 — без ограничения
+	MaxConcurrentHedges int
+	// Registerer — реестр для метрик хеджирования; nil — без метрик
+	Registerer prometheus.Registerer
+}
+
+// Hedger отправляет дополнительные попытки идемпотентных вызовов,
+// если первая не ответила за заданное время
+type Hedger struct {
+	cfg   HedgingConfig
+	slots chan struct{}
+
+	hedges *prometheus.CounterVec
+	wins   *prometheus.CounterVec
+
+	mu        sync.Mutex
+	latencies map[string]*latencyWindow
+}
+
+// NewHedger создает Hedger и регистрирует его метрики
+func NewHedger(cfg HedgingConfig) (*Hedger, error) {
+	if cfg.MaxHedges <= 0 {
+		cfg.MaxHedges = 1
+	}
+
+	h := &Hedger{cfg: cfg, latencies: make(map[string]*latencyWindow)}
+	if cfg.MaxConcurrentHedges > 0 {
+		h.slots = make(chan struct{}, cfg.MaxConcurrentHedges)
+	}
+
+	if cfg.Registerer != nil {
+		h.hedges = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_client_hedged_requests_total",
+				Help: "Количество дополнительных попыток, отправленных при хеджировании",
+			},
+			[]string{"method"},
+		)
+		h.wins = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_client_hedge_wins_total",
+				Help: "Количество вызовов, в которых первой ответила дополнительная попытка",
+			},
+			[]string{"method"},
+		)
+		if err := cfg.Registerer.Register(h.hedges); err != nil {
+			return nil, fmt.Errorf("failed to register hedged requests: %w", err)
+		}
+		if err := cfg.Registerer.Register(h.wins); err != nil {
+			return nil, fmt.Errorf("failed to register hedge wins: %w", err)
+		}
+	}
+
+	return h, nil
+}
+
+// WithHedging включает хеджирование для идемпотентных методов клиента
+// (см. WithIdempotentMethods и Idempotent)
+func WithHedging(h *Hedger) ClientOption {
+	return func(c *Client) {
+		c.hedger = h
+	}
+}
+
+// attemptResult — результат одной попытки хеджированного вызова
+type attemptResult struct {
+	index   int
+	resp    proto.Message
+	trailer metadata.MD
+	err     error
+}
+
+// invoke выполняет вызов с дополнительными попытками. Побеждает первый успешный ответ,
+// остальные попытки отменяются. Ошибка с кодом, который политика повторов метода
+// не считает повторяемым, завершает вызов сразу, как в gRPC hedging.
+func (h *Hedger) invoke(ctx context.Context, c *Client, policy RetryPolicy, timeout time.Duration, method string, req interface{}, resp proto.Message, trailer *metadata.MD, opts []grpc.CallOption) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan attemptResult, h.cfg.MaxHedges+1)
+	start := time.Now()
+
+	launch := func(index int) {
+		go func() {
+			out := resp.ProtoReflect().New().Interface()
+			var md metadata.MD
+			err := c.invokeAttempt(ctx, timeout, method, req, out, append(slices.Clip(opts), grpc.Trailer(&md))...)
+			results <- attemptResult{index: index, resp: out, trailer: md, err: err}
+		}()
+	}
+
+	launch(0)
+	launched, pending := 1, 1
+	timer := time.NewTimer(h.delay(method))
+	defer timer.Stop()
+
+	var last attemptResult
+	for {
+		select {
+		case <-timer.C:
+			if launched <= h.cfg.MaxHedges && h.acquire() {
+				h.observeHedge(method)
+				launch(launched)
+				launched++
+				pending++
+				defer h.release()
+			}
+			if launched <= h.cfg.MaxHedges {
+				timer.Reset(h.delay(method))
+			}
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				h.observeLatency(method, time.Since(start))
+				if res.index > 0 {
+					h.observeWin(method)
+				}
+				*trailer = res.trailer
+				proto.Reset(resp)
+				proto.Merge(resp, res.resp)
+				return nil
+			}
+			last = res
+			if !policy.retryable(status.Code(res.err)) {
+				*trailer = res.trailer
+				return res.err
+			}
+			if pending > 0 {
+				continue
+			}
+			// Все отправленные попытки завершились повторяемой ошибкой: следующую отправляем сразу
+			if launched <= h.cfg.MaxHedges && ctx.Err() == nil && h.acquire() {
+				h.observeHedge(method)
+				launch(launched)
+				launched++
+				pending++
+				defer h.release()
+				continue
+			}
+			*trailer = last.trailer
+			return last.err
+		}
+	}
+}
+
+// delay возвращает задержку перед следующей попыткой
+func (h *Hedger) delay(method string) time.Duration {
+	if !h.cfg.AdaptiveDelay {
+		return h.cfg.Delay
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if w, ok := h.latencies[method]; ok && len(w.samples) >= minLatencySamples {
+		return w.p95()
+	}
+	return h.cfg.Delay
+}
+
+// acquire занимает слот для дополнительной попытки, не блокируясь
+func (h *Hedger) acquire() bool {
+	if h.slots == nil {
+		return true
+	}
+	select {
+	case h.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (h *Hedger) release() {
+	if h.slots != nil {
+		<-h.slots
+	}
+}
+
+func (h *Hedger) observeLatency(method string, d time.Duration) {
+	if !h.cfg.AdaptiveDelay {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	w, ok := h.latencies[method]
+	if !ok {
+		w = &latencyWindow{}
+		h.latencies[method] = w
+	}
+	w.add(d)
+}
+
+func (h *Hedger) observeHedge(method string) {
+	if h.hedges != nil {
+		h.hedges.WithLabelValues(method).Inc()
+	}
+}
+
+func (h *Hedger) observeWin(method string) {
+	if h.wins != nil {
+		h.wins.WithLabelValues(method).Inc()
+	}
+}
+
+// latencyWindow хранит последние замеры задержки метода
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+func (w *latencyWindow) add(d time.Duration) {
+	if len(w.samples) < latencyWindowSize {
+		w.samples = append(w.samples, d)
+		return
+	}
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % latencyWindowSize
+}
+
+func (w *latencyWindow) p95() time.Duration {
+	sorted := slices.Clone(w.samples)
+	slices.Sort(sorted)
+	return sorted[(len(sorted)*95)/100]
+}
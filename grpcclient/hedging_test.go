@@ -0,0 +1,57 @@
+package grpcclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func newHedgingTestClient(t *testing.T, method string) *Client {
+	t.Helper()
+	hedger, err := NewHedger(HedgingConfig{Delay: time.Hour, MaxHedges: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Одна попытка на уровне повторов: дополнительные вызовы делает только Hedger
+	policy := DefaultRetryPolicy(0)
+	return NewMockClient(WithHedging(hedger), WithRetryPolicy(policy), WithIdempotentMethods(method))
+}
+
+func TestHedgingStopsOnNonRetryableCode(t *testing.T) {
+	const method = "/pkg.Service/Get"
+	client := newHedgingTestClient(t, method)
+	client.AddScript(method, MockStep{Err: status.Error(codes.InvalidArgument, "некорректный запрос")})
+
+	err := client.invoke(context.Background(), method, wrapperspb.String("req"), &wrapperspb.StringValue{}, nil)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("код %s, ожидался InvalidArgument", status.Code(err))
+	}
+	if calls := len(client.CallsTo(method)); calls != 1 {
+		t.Fatalf("выполнено %d вызовов, ожидался 1", calls)
+	}
+}
+
+func TestHedgingRetriesRetryableCode(t *testing.T) {
+	const method = "/pkg.Service/Get"
+	client := newHedgingTestClient(t, method)
+	client.AddScript(method,
+		MockStep{Err: status.Error(codes.Unavailable, "недоступен")},
+		MockStep{Err: status.Error(codes.Unavailable, "недоступен")},
+		MockStep{Response: wrapperspb.String("ok")},
+	)
+
+	resp := &wrapperspb.StringValue{}
+	if err := client.invoke(context.Background(), method, wrapperspb.String("req"), resp, nil); err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetValue() != "ok" {
+		t.Fatalf("ответ %q, ожидался ok", resp.GetValue())
+	}
+	if calls := len(client.CallsTo(method)); calls != 3 {
+		t.Fatalf("выполнено %d вызовов, ожидалось 3", calls)
+	}
+}
@@ -0,0 +1,83 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader — ключ метаданных с идентификатором запроса
+const RequestIDHeader = "x-request-id"
+
+// RequestIDFromContext возвращает идентификатор запроса из исходящих или входящих метаданных
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		if values := md.Get(RequestIDHeader); len(values) > 0 {
+			return values[0], true
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDHeader); len(values) > 0 {
+			return values[0], true
+		}
+	}
+	return "", false
+}
+
+// RequestIDInterceptor добавляет x-request-id к исходящему вызову.
+// Идентификатор берется из входящего запроса (если вызов делается из обработчика сервера)
+// или генерируется заново.
+//
+// Интерцептор соединения вызывается для каждой попытки: без идентификатора во входящем
+// или исходящем контексте повторы и хеджированные копии одного вызова получат разные x-request-id.
+// Для grpcclient.Client используйте grpcclient.WithRequestID — идентификатор создается
+// один раз на вызов, до повторов.
+func RequestIDInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		return invoker(WithRequestID(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamRequestIDInterceptor добавляет x-request-id к исходящему потоку
+func StreamRequestIDInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		return streamer(WithRequestID(ctx), desc, cc, method, opts...)
+	}
+}
+
+// WithRequestID добавляет x-request-id в исходящие метаданные, если его там еще нет.
+// Идентификатор берется из входящего запроса или генерируется заново.
+func WithRequestID(ctx context.Context) context.Context {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(RequestIDHeader)) > 0 {
+		return ctx
+	}
+
+	requestID, ok := RequestIDFromContext(ctx)
+	if !ok {
+		requestID = newRequestID()
+	}
+	return metadata.AppendToOutgoingContext(ctx, RequestIDHeader, requestID)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,43 @@
+package grpcclient
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Client реализует grpc.ClientConnInterface, поэтому его можно передавать
+// в конструкторы сгенерированных клиентов (например, NewUsersServiceClient)
+var _ grpc.ClientConnInterface = (*Client)(nil)
+
+// Invoke выполняет унарный вызов с метаданными, таймаутом и повторными попытками клиента
+func (c *Client) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	return c.invoke(ctx, method, args, reply, c.metadata, opts...)
+}
+
+// NewStream открывает поток с метаданными клиента
+func (c *Client) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return c.OpenStream(ctx, desc, method, WithCallOptions(opts...))
+}
+
+// Invoke выполняет унарный вызов и возвращает ответ нужного типа:
+//
+//	resp, err := grpcclient.Invoke[pb.Resp](ctx, client, pb.UsersService_GetStatusInfo_FullMethodName, req)
+func Invoke[Resp any, PResp interface {
+	*Resp
+	proto.Message
+}](ctx context.Context, c *Client, method string, req proto.Message, opts ...grpc.CallOption) (PResp, error) {
+	resp := PResp(new(Resp))
+	if err := c.Invoke(ctx, method, req, resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Bind создает сгенерированный клиент сервиса поверх Client:
+//
+//	users := grpcclient.Bind(client, pb.NewUsersServiceClient)
+func Bind[T any](c *Client, newServiceClient func(grpc.ClientConnInterface) T) T {
+	return newServiceClient(c)
+}
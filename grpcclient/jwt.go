@@ -0,0 +1,155 @@
+package grpcclient
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Алгоритмы подписи JWT
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmES256 = "ES256"
+)
+
+// JWTConfig описывает самоподписанный JWT
+type JWTConfig struct {
+	// Algorithm — HS256, RS256 или ES256
+	Algorithm string
+	// Key — []byte для HS256, *rsa.PrivateKey для RS256, *ecdsa.PrivateKey (P-256) для ES256
+	Key interface{}
+	// KeyID попадает в заголовок kid, если задан
+	KeyID string
+
+	Issuer   string
+	Subject  string
+	Audience string
+	// Claims — дополнительные поля токена
+	Claims map[string]interface{}
+
+	// TTL — срок действия токена; по умолчанию 1 час
+	TTL time.Duration
+	// RefreshBefore — за сколько до истечения токен подписывается заново; по умолчанию 1 минута,
+	// но не больше половины TTL
+	RefreshBefore time.Duration
+}
+
+// NewJWTSigner создает провайдер, подписывающий JWT и обновляющий его до истечения срока
+func NewJWTSigner(cfg JWTConfig) (CredentialsProvider, error) {
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Hour
+	}
+	if cfg.RefreshBefore <= 0 {
+		cfg.RefreshBefore = time.Minute
+	}
+
+	sign, err := jwtSigner(cfg.Algorithm, cfg.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenCache{
+		refreshBefore: cfg.RefreshBefore,
+		fetch: func(context.Context) (string, time.Time, error) {
+			return signJWT(cfg, sign, time.Now())
+		},
+	}, nil
+}
+
+// jwtSigner возвращает функцию подписи для алгоритма и ключа
+func jwtSigner(algorithm string, key interface{}) (func(data []byte) ([]byte, error), error) {
+	switch algorithm {
+	case JWTAlgorithmHS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("для %s нужен ключ []byte, получено %T", algorithm, key)
+		}
+		return func(data []byte) ([]byte, error) {
+			mac := hmac.New(sha256.New, secret)
+			mac.Write(data)
+			return mac.Sum(nil), nil
+		}, nil
+	case JWTAlgorithmRS256:
+		privateKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("для %s нужен ключ *rsa.PrivateKey, получено %T", algorithm, key)
+		}
+		return func(data []byte) ([]byte, error) {
+			digest := sha256.Sum256(data)
+			return rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+		}, nil
+	case JWTAlgorithmES256:
+		privateKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("для %s нужен ключ *ecdsa.PrivateKey, получено %T", algorithm, key)
+		}
+		if privateKey.Curve.Params().BitSize != 256 {
+			return nil, fmt.Errorf("для %s нужен ключ на кривой P-256", algorithm)
+		}
+		return func(data []byte) ([]byte, error) {
+			digest := sha256.Sum256(data)
+			r, s, err := ecdsa.Sign(rand.Reader, privateKey, digest[:])
+			if err != nil {
+				return nil, err
+			}
+			// Подпись JWS — конкатенация r и s фиксированной длины
+			signature := make([]byte, 64)
+			r.FillBytes(signature[:32])
+			s.FillBytes(signature[32:])
+			return signature, nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("неподдерживаемый алгоритм JWT: %q", algorithm)
+	}
+}
+
+// signJWT формирует и подписывает токен
+func signJWT(cfg JWTConfig, sign func([]byte) ([]byte, error), now time.Time) (string, time.Time, error) {
+	header := map[string]interface{}{"alg": cfg.Algorithm, "typ": "JWT"}
+	if cfg.KeyID != "" {
+		header["kid"] = cfg.KeyID
+	}
+
+	expiry := now.Add(cfg.TTL)
+	claims := make(map[string]interface{}, len(cfg.Claims)+5)
+	for k, v := range cfg.Claims {
+		claims[k] = v
+	}
+	claims["iat"] = now.Unix()
+	claims["exp"] = expiry.Unix()
+	if cfg.Issuer != "" {
+		claims["iss"] = cfg.Issuer
+	}
+	if cfg.Subject != "" {
+		claims["sub"] = cfg.Subject
+	}
+	if cfg.Audience != "" {
+		claims["aud"] = cfg.Audience
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("ошибка формирования заголовка JWT: %w", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("ошибка формирования полей JWT: %w", err)
+	}
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(headerJSON) + "." + enc.EncodeToString(claimsJSON)
+	signature, err := sign([]byte(signingInput))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("ошибка подписи JWT: %w", err)
+	}
+	return signingInput + "." + enc.EncodeToString(signature), expiry, nil
+}
@@ -0,0 +1,281 @@
+package grpcclient
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SaturationMode определяет поведение при исчерпании лимитов
+type SaturationMode int
+
+const (
+	// SaturationBlock — ждать освобождения лимита до дедлайна контекста
+	SaturationBlock SaturationMode = iota
+	// SaturationFail — сразу завершать вызов с кодом ResourceExhausted
+	SaturationFail
+)
+
+// RateLimit описывает token bucket: Rate запросов в секунду с допустимым всплеском Burst
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// LimiterConfig описывает ограничения исходящих вызовов
+type LimiterConfig struct {
+	// MaxInFlight — максимум одновременных вызовов клиента, включая ожидающие
+	// повторной попытки; 0 — без ограничения
+	MaxInFlight int
+	// MethodMaxInFlight — максимум одновременных вызовов по методам
+	MethodMaxInFlight map[string]int
+	// RateLimit — ограничение частоты вызовов клиента; нулевое значение — без ограничения
+	RateLimit RateLimit
+	// MethodRateLimits — ограничения частоты вызовов по методам
+	MethodRateLimits map[string]RateLimit
+	// OnSaturation — поведение при исчерпании лимитов
+	OnSaturation SaturationMode
+	// Registerer — реестр для метрик ограничителя; nil — без метрик
+	Registerer prometheus.Registerer
+}
+
+// Limiter ограничивает число одновременных вызовов и их частоту
+type Limiter struct {
+	cfg LimiterConfig
+
+	inFlight        chan struct{}
+	methodInFlight  map[string]chan struct{}
+	rate            *tokenBucket
+	methodRates     map[string]*tokenBucket
+	inFlightGauge   *prometheus.GaugeVec
+	queuedGauge     *prometheus.GaugeVec
+	rejectedCounter *prometheus.CounterVec
+}
+
+// NewLimiter создает ограничитель и регистрирует его метрики
+func NewLimiter(cfg LimiterConfig) (*Limiter, error) {
+	l := &Limiter{
+		cfg:            cfg,
+		methodInFlight: make(map[string]chan struct{}),
+		methodRates:    make(map[string]*tokenBucket),
+	}
+
+	if cfg.MaxInFlight > 0 {
+		l.inFlight = make(chan struct{}, cfg.MaxInFlight)
+	}
+	for method, limit := range cfg.MethodMaxInFlight {
+		if limit > 0 {
+			l.methodInFlight[normalizeMethod(method)] = make(chan struct{}, limit)
+		}
+	}
+	l.rate = newTokenBucket(cfg.RateLimit)
+	for method, limit := range cfg.MethodRateLimits {
+		if bucket := newTokenBucket(limit); bucket != nil {
+			l.methodRates[normalizeMethod(method)] = bucket
+		}
+	}
+
+	if cfg.Registerer != nil {
+		l.inFlightGauge = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "grpc_client_inflight_requests",
+				Help: "Количество выполняющихся исходящих gRPC запросов",
+			},
+			[]string{"method"},
+		)
+		l.queuedGauge = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "grpc_client_queued_requests",
+				Help: "Количество исходящих gRPC запросов, ожидающих освобождения лимита",
+			},
+			[]string{"method"},
+		)
+		l.rejectedCounter = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_client_limiter_rejected_total",
+				Help: "Количество исходящих gRPC запросов, отклоненных ограничителем",
+			},
+			[]string{"method"},
+		)
+		for name, collector := range map[string]prometheus.Collector{
+			"inflight requests": l.inFlightGauge,
+			"queued requests":   l.queuedGauge,
+			"limiter rejected":  l.rejectedCounter,
+		} {
+			if err := cfg.Registerer.Register(collector); err != nil {
+				return nil, fmt.Errorf("failed to register %s: %w", name, err)
+			}
+		}
+	}
+
+	return l, nil
+}
+
+// WithLimiter включает ограничение унарных вызовов клиента.
+// Лимиты применяются к вызову целиком, повторные попытки не занимают дополнительных слотов
+// и токенов. Слот одновременных вызовов остается занятым до завершения вызова,
+// в том числе во время пауз между повторными попытками.
+func WithLimiter(l *Limiter) ClientOption {
+	return func(c *Client) {
+		c.limiter = l
+	}
+}
+
+// acquire занимает лимиты для вызова метода. Возвращает функцию освобождения.
+func (l *Limiter) acquire(ctx context.Context, method string) (func(), error) {
+	method = normalizeMethod(method)
+
+	l.addGauge(l.queuedGauge, method, 1)
+	release, err := l.wait(ctx, method)
+	l.addGauge(l.queuedGauge, method, -1)
+	if err != nil {
+		if l.rejectedCounter != nil {
+			l.rejectedCounter.WithLabelValues(method).Inc()
+		}
+		return nil, err
+	}
+
+	l.addGauge(l.inFlightGauge, method, 1)
+	return func() {
+		l.addGauge(l.inFlightGauge, method, -1)
+		release()
+	}, nil
+}
+
+// wait получает токены и слоты для вызова. Если какой-либо лимит не получен,
+// уже взятые токены возвращаются: отклоненный вызов не расходует частоту.
+func (l *Limiter) wait(ctx context.Context, method string) (func(), error) {
+	var taken []*tokenBucket
+	refund := func() {
+		for _, bucket := range taken {
+			bucket.cancel()
+		}
+	}
+	for _, bucket := range []*tokenBucket{l.rate, l.methodRates[method]} {
+		if bucket == nil {
+			continue
+		}
+		if err := bucket.take(ctx, l.cfg.OnSaturation); err != nil {
+			refund()
+			return nil, err
+		}
+		taken = append(taken, bucket)
+	}
+
+	var held []chan struct{}
+	release := func() {
+		for _, sem := range held {
+			<-sem
+		}
+	}
+	for _, sem := range []chan struct{}{l.inFlight, l.methodInFlight[method]} {
+		if sem == nil {
+			continue
+		}
+		if err := l.enter(ctx, sem); err != nil {
+			release()
+			refund()
+			return nil, err
+		}
+		held = append(held, sem)
+	}
+	return release, nil
+}
+
+// enter занимает слот семафора с учетом режима насыщения
+func (l *Limiter) enter(ctx context.Context, sem chan struct{}) error {
+	select {
+	case sem <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if l.cfg.OnSaturation == SaturationFail {
+		return status.Error(codes.ResourceExhausted, "превышено число одновременных вызовов")
+	}
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+func (l *Limiter) addGauge(gauge *prometheus.GaugeVec, method string, delta float64) {
+	if gauge != nil {
+		gauge.WithLabelValues(method).Add(delta)
+	}
+}
+
+// tokenBucket — ограничитель частоты по алгоритму token bucket
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	if limit.Rate <= 0 {
+		return nil
+	}
+	burst := math.Max(float64(limit.Burst), 1)
+	return &tokenBucket{rate: limit.Rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// reserve забирает токен и возвращает время ожидания до его появления
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.tokens+now.Sub(b.last).Seconds()*b.rate, b.burst)
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel возвращает токен, забранный reserve
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.tokens+1, b.burst)
+}
+
+// take получает токен с учетом режима насыщения
+func (b *tokenBucket) take(ctx context.Context, mode SaturationMode) error {
+	if b == nil {
+		return nil
+	}
+
+	now := time.Now()
+	wait := b.reserve(now)
+	if wait == 0 {
+		return nil
+	}
+
+	exhausted := status.Error(codes.ResourceExhausted, "превышена допустимая частота вызовов")
+	if mode == SaturationFail {
+		b.cancel()
+		return exhausted
+	}
+	// Токен не появится до дедлайна: нет смысла ждать
+	if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+		b.cancel()
+		return exhausted
+	}
+	if err := sleepContext(ctx, wait); err != nil {
+		b.cancel()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package grpcclient
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLimiterRefundsTokensOnRejection(t *testing.T) {
+	const method = "/pkg.Service/Get"
+	l, err := NewLimiter(LimiterConfig{
+		RateLimit:         RateLimit{Rate: 0.001, Burst: 2},
+		MethodMaxInFlight: map[string]int{method: 1},
+		OnSaturation:      SaturationFail,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release, err := l.acquire(context.Background(), method)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Слот метода занят: вызов отклоняется, токен клиента должен вернуться
+	for i := 0; i < 3; i++ {
+		if _, err := l.acquire(context.Background(), method); status.Code(err) != codes.ResourceExhausted {
+			t.Fatalf("код %s, ожидался ResourceExhausted", status.Code(err))
+		}
+	}
+	release()
+
+	if _, err := l.acquire(context.Background(), method); err != nil {
+		t.Fatalf("отклоненные вызовы израсходовали токены: %v", err)
+	}
+}
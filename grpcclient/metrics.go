@@ -0,0 +1,145 @@
+package grpcclient
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/status"
+)
+
+// ClientMetrics — Prometheus метрики вызовов и потоков клиента. Имена повторяют
+// метрики grpcserver с префиксом grpc_client_. Унарный вызов учитывается целиком,
+// вместе с повторными попытками; поток — от открытия до завершения.
+type ClientMetrics struct {
+	requests       *prometheus.CounterVec
+	duration       *prometheus.HistogramVec
+	retries        *prometheus.CounterVec
+	attempts       *prometheus.HistogramVec
+	streamSent     *prometheus.CounterVec
+	streamReceived *prometheus.CounterVec
+	streamDuration *prometheus.HistogramVec
+}
+
+// NewClientMetrics создает метрики клиента и регистрирует их в reg.
+// Один ClientMetrics можно использовать в нескольких клиентах.
+func NewClientMetrics(reg prometheus.Registerer) (*ClientMetrics, error) {
+	m := &ClientMetrics{
+		requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_client_requests_total",
+				Help: "Количество исходящих gRPC вызовов и потоков по итоговому коду ответа",
+			},
+			[]string{"method", "code"},
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "grpc_client_request_duration_seconds",
+				Help:    "Длительность исходящих унарных gRPC вызовов с учетом повторных попыток в секундах",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"method"},
+		),
+		retries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_client_retries_total",
+				Help: "Количество повторных попыток исходящих gRPC вызовов",
+			},
+			[]string{"method"},
+		),
+		attempts: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "grpc_client_attempts_per_request",
+				Help:    "Число попыток на один исходящий gRPC вызов",
+				Buckets: []float64{1, 2, 3, 4, 5, 7, 10},
+			},
+			[]string{"method"},
+		),
+		streamSent: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_client_stream_messages_sent_total",
+				Help: "Количество сообщений, отправленных клиентом в gRPC потоках",
+			},
+			[]string{"method"},
+		),
+		streamReceived: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_client_stream_messages_received_total",
+				Help: "Количество сообщений, полученных клиентом в gRPC потоках",
+			},
+			[]string{"method"},
+		),
+		streamDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "grpc_client_stream_duration_seconds",
+				Help:    "Длительность исходящих gRPC потоков в секундах",
+				Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300, 900, 3600},
+			},
+			[]string{"method"},
+		),
+	}
+
+	for name, collector := range map[string]prometheus.Collector{
+		"requests":       m.requests,
+		"duration":       m.duration,
+		"retries":        m.retries,
+		"attempts":       m.attempts,
+		"streamSent":     m.streamSent,
+		"streamReceived": m.streamReceived,
+		"streamDuration": m.streamDuration,
+	} {
+		if err := reg.Register(collector); err != nil {
+			return nil, fmt.Errorf("failed to register %s: %w", name, err)
+		}
+	}
+	return m, nil
+}
+
+// WithMetrics включает сбор метрик вызовов и потоков клиента
+func WithMetrics(m *ClientMetrics) ClientOption {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
+
+// observe учитывает завершенный вызов; attempts — число выполненных попыток
+func (m *ClientMetrics) observe(method string, start time.Time, attempts int, err error) {
+	if m == nil {
+		return
+	}
+
+	method = normalizeMethod(method)
+	m.requests.WithLabelValues(method, status.Code(err).String()).Inc()
+	m.duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	m.attempts.WithLabelValues(method).Observe(float64(attempts))
+	if attempts > 1 {
+		m.retries.WithLabelValues(method).Add(float64(attempts - 1))
+	}
+}
+
+// observeStream учитывает завершенный поток
+func (m *ClientMetrics) observeStream(method string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+
+	method = normalizeMethod(method)
+	m.requests.WithLabelValues(method, status.Code(err).String()).Inc()
+	m.streamDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// sent учитывает сообщение, отправленное в поток
+func (m *ClientMetrics) sent(method string) {
+	if m == nil {
+		return
+	}
+	m.streamSent.WithLabelValues(normalizeMethod(method)).Inc()
+}
+
+// received учитывает сообщение, полученное из потока
+func (m *ClientMetrics) received(method string) {
+	if m == nil {
+		return
+	}
+	m.streamReceived.WithLabelValues(normalizeMethod(method)).Inc()
+}
@@ -0,0 +1,237 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	sumMethod   = "/test.Numbers/Sum"
+	countMethod = "/test.Numbers/Count"
+	flakyMethod = "/test.Numbers/Flaky"
+)
+
+// numbersServer считает открытия потока Flaky
+type numbersServer struct {
+	flakyOpens atomic.Int64
+}
+
+// numbersService — сервис с клиентским (Sum) и серверными (Count, Flaky) потоками
+var numbersService = grpc.ServiceDesc{
+	ServiceName: "test.Numbers",
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Sum",
+			ClientStreams: true,
+			Handler: func(_ interface{}, stream grpc.ServerStream) error {
+				var sum int64
+				for {
+					v := &wrapperspb.Int64Value{}
+					if err := stream.RecvMsg(v); errors.Is(err, io.EOF) {
+						return stream.SendMsg(wrapperspb.Int64(sum))
+					} else if err != nil {
+						return err
+					}
+					sum += v.GetValue()
+				}
+			},
+		},
+		{
+			StreamName:    "Count",
+			ServerStreams: true,
+			Handler: func(_ interface{}, stream grpc.ServerStream) error {
+				n := &wrapperspb.Int64Value{}
+				if err := stream.RecvMsg(n); err != nil {
+					return err
+				}
+				for i := int64(1); i <= n.GetValue(); i++ {
+					if err := stream.SendMsg(wrapperspb.Int64(i)); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			// Flaky отправляет числа от запрошенного до 5; первый поток обрывается
+			// с кодом Unavailable после двух сообщений
+			StreamName:    "Flaky",
+			ServerStreams: true,
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				from := &wrapperspb.Int64Value{}
+				if err := stream.RecvMsg(from); err != nil {
+					return err
+				}
+				first := srv.(*numbersServer).flakyOpens.Add(1) == 1
+				for i := from.GetValue(); i <= 5; i++ {
+					if first && i == from.GetValue()+2 {
+						return status.Error(codes.Unavailable, "соединение потеряно")
+					}
+					if err := stream.SendMsg(wrapperspb.Int64(i)); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+	},
+}
+
+func newObservedClient(t *testing.T, opts ...ClientOption) (*Client, *ClientMetrics, *tracetest.InMemoryExporter) {
+	client, metrics, exporter, _ := newNumbersClient(t, opts...)
+	return client, metrics, exporter
+}
+
+func newNumbersClient(t *testing.T, opts ...ClientOption) (*Client, *ClientMetrics, *tracetest.InMemoryExporter, *numbersServer) {
+	t.Helper()
+	server := &numbersServer{}
+	srv := grpc.NewServer()
+	srv.RegisterService(&numbersService, server)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	metrics, err := NewClientMetrics(prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts = append([]ClientOption{WithMetrics(metrics), WithTracing(TracingConfig{TracerProvider: provider})}, opts...)
+	client, err := NewClient(lis.Addr().String(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, metrics, exporter, server
+}
+
+func TestClientStreamEndsOnResponse(t *testing.T) {
+	client, metrics, exporter := newObservedClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.OpenStream(ctx, &grpc.StreamDesc{ClientStreams: true}, sumMethod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := int64(1); i <= 3; i++ {
+		if err := Send(stream, wrapperspb.Int64(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := Recv[wrapperspb.Int64Value](stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.GetValue() != 6 {
+		t.Fatalf("сумма %d, ожидалось 6", sum.GetValue())
+	}
+
+	// Поток не дочитывается до EOF и не закрывается: спан завершается с получением ответа
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "test.Numbers/Sum" {
+		t.Fatalf("завершенные спаны: %v", spans)
+	}
+	if stream.Context().Err() == nil {
+		t.Fatal("контекст потока не отменен после получения ответа")
+	}
+
+	if got := testutil.ToFloat64(metrics.requests.WithLabelValues(sumMethod, "OK")); got != 1 {
+		t.Fatalf("grpc_client_requests_total = %v, ожидалось 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.streamSent.WithLabelValues(sumMethod)); got != 3 {
+		t.Fatalf("grpc_client_stream_messages_sent_total = %v, ожидалось 3", got)
+	}
+	if got := testutil.ToFloat64(metrics.streamReceived.WithLabelValues(sumMethod)); got != 1 {
+		t.Fatalf("grpc_client_stream_messages_received_total = %v, ожидалось 1", got)
+	}
+	if got := testutil.CollectAndCount(metrics.streamDuration); got != 1 {
+		t.Fatalf("grpc_client_stream_duration_seconds: %d серий, ожидалась 1", got)
+	}
+}
+
+func TestServerStreamEndsOnEOF(t *testing.T) {
+	client, metrics, exporter := newObservedClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.OpenServerStream(ctx, countMethod, wrapperspb.Int64(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; ; i++ {
+		_, err := Recv[wrapperspb.Int64Value](stream)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		// До конца потока спан открыт
+		if n := len(exporter.GetSpans()); n != 0 {
+			t.Fatalf("после сообщения %d завершено %d спанов", i, n)
+		}
+	}
+
+	if n := len(exporter.GetSpans()); n != 1 {
+		t.Fatalf("завершено %d спанов, ожидался 1", n)
+	}
+	if got := testutil.ToFloat64(metrics.requests.WithLabelValues(countMethod, "OK")); got != 1 {
+		t.Fatalf("grpc_client_requests_total = %v, ожидалось 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.streamReceived.WithLabelValues(countMethod)); got != 3 {
+		t.Fatalf("grpc_client_stream_messages_received_total = %v, ожидалось 3", got)
+	}
+}
+
+func TestUnaryCallMetrics(t *testing.T) {
+	metrics, err := NewClientMetrics(prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	const method = "/pkg.Service/Get"
+	client := NewMockClient(WithMetrics(metrics), WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+	}))
+	client.AddScript(method,
+		MockStep{Err: status.Error(codes.Unavailable, "недоступен")},
+		MockStep{Response: wrapperspb.String("ok")},
+	)
+
+	if err := client.Invoke(context.Background(), method, wrapperspb.String("req"), &wrapperspb.StringValue{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(metrics.requests.WithLabelValues(method, "OK")); got != 1 {
+		t.Fatalf("grpc_client_requests_total = %v, ожидалось 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.retries.WithLabelValues(method)); got != 1 {
+		t.Fatalf("grpc_client_retries_total = %v, ожидалось 1", got)
+	}
+}
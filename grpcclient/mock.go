@@ -0,0 +1,207 @@
+package grpcclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// MockStep описывает один шаг сценария ответа на вызов метода
+type MockStep struct {
+	// Response — ответ, возвращаемый при отсутствии ошибки
+	Response proto.Message
+	// Err — ошибка вызова, например status.Error(codes.Unavailable, "...")
+	Err error
+	// Delay — задержка перед ответом; учитывает отмену контекста
+	Delay time.Duration
+}
+
+// mockScript хранит шаги сценария и текущую позицию
+type mockScript struct {
+	steps []MockStep
+	next  int
+}
+
+// Call описывает вызов, обработанный без обращения к сети
+type Call struct {
+	Method   string
+	Request  interface{}
+	Metadata metadata.MD
+}
+
+// TestingT — часть testing.TB, необходимая для проверок
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// NewMockClient создает клиент без сетевого соединения.
+// Вызовы обслуживаются обработчиками из AddHandler и сценариями из AddScript,
+// вызов незарегистрированного метода завершается с кодом Unimplemented.
+func NewMockClient(opts ...ClientOption) *Client {
+	return newClient(opts...)
+}
+
+// AddHandler добавляет пользовательский обработчик для метода.
+// Вызовы метода обрабатываются без обращения к сети.
+func (c *Client) AddHandler(method string, handler MethodHandler) {
+	c.mockMu.Lock()
+	defer c.mockMu.Unlock()
+	c.methodHandlers[normalizeMethod(method)] = handler
+}
+
+// AddScript задает последовательность ответов для метода.
+// Каждый вызов получает следующий шаг, после исчерпания повторяется последний.
+// Сценарий имеет приоритет над обработчиком из AddHandler.
+func (c *Client) AddScript(method string, steps ...MockStep) {
+	c.mockMu.Lock()
+	defer c.mockMu.Unlock()
+	c.mockScripts[normalizeMethod(method)] = &mockScript{steps: steps}
+}
+
+// Calls возвращает все вызовы, обработанные без обращения к сети
+func (c *Client) Calls() []Call {
+	c.mockMu.Lock()
+	defer c.mockMu.Unlock()
+	return append([]Call(nil), c.calls...)
+}
+
+// CallsTo возвращает вызовы указанного метода
+func (c *Client) CallsTo(method string) []Call {
+	method = normalizeMethod(method)
+
+	var calls []Call
+	for _, call := range c.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// ResetCalls очищает журнал вызовов
+func (c *Client) ResetCalls() {
+	c.mockMu.Lock()
+	defer c.mockMu.Unlock()
+	c.calls = nil
+}
+
+// AssertCalled проверяет, что метод вызывался хотя бы один раз
+func (c *Client) AssertCalled(t TestingT, method string) bool {
+	t.Helper()
+	if len(c.CallsTo(method)) == 0 {
+		t.Errorf("ожидался вызов метода %s", method)
+		return false
+	}
+	return true
+}
+
+// AssertNotCalled проверяет, что метод не вызывался
+func (c *Client) AssertNotCalled(t TestingT, method string) bool {
+	t.Helper()
+	if n := len(c.CallsTo(method)); n != 0 {
+		t.Errorf("метод %s не должен был вызываться, вызовов: %d", method, n)
+		return false
+	}
+	return true
+}
+
+// AssertNumberOfCalls проверяет количество вызовов метода
+func (c *Client) AssertNumberOfCalls(t TestingT, method string, expected int) bool {
+	t.Helper()
+	if n := len(c.CallsTo(method)); n != expected {
+		t.Errorf("метод %s: ожидалось вызовов %d, получено %d", method, expected, n)
+		return false
+	}
+	return true
+}
+
+// mocked проверяет, обслуживается ли метод без обращения к сети
+func (c *Client) mocked(method string) bool {
+	key := normalizeMethod(method)
+
+	c.mockMu.Lock()
+	defer c.mockMu.Unlock()
+	_, hasHandler := c.methodHandlers[key]
+	_, hasScript := c.mockScripts[key]
+	return hasHandler || hasScript || c.conn == nil
+}
+
+// invokeMock обрабатывает вызов зарегистрированным сценарием или обработчиком.
+// Возвращает false, если для метода ничего не зарегистрировано и клиент имеет соединение.
+func (c *Client) invokeMock(ctx context.Context, method string, req, resp interface{}) (bool, error) {
+	key := normalizeMethod(method)
+
+	c.mockMu.Lock()
+	handler, hasHandler := c.methodHandlers[key]
+	script, hasScript := c.mockScripts[key]
+	if !hasHandler && !hasScript && c.conn != nil {
+		c.mockMu.Unlock()
+		return false, nil
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	call := Call{Method: key, Request: req, Metadata: md.Copy()}
+	if m, ok := req.(proto.Message); ok {
+		call.Request = proto.Clone(m)
+	}
+	c.calls = append(c.calls, call)
+
+	var step MockStep
+	if hasScript && len(script.steps) > 0 {
+		step = script.steps[min(script.next, len(script.steps)-1)]
+		script.next++
+	}
+	c.mockMu.Unlock()
+
+	switch {
+	case hasScript:
+		if err := sleepContext(ctx, step.Delay); err != nil {
+			return true, err
+		}
+		if step.Err != nil {
+			return true, step.Err
+		}
+		return true, copyResponse(resp, step.Response)
+	case hasHandler:
+		out, err := handler(ctx, req)
+		if err != nil {
+			return true, err
+		}
+		return true, copyResponse(resp, out)
+	default:
+		return true, status.Errorf(codes.Unimplemented, "обработчик метода %s не зарегистрирован", key)
+	}
+}
+
+// copyResponse копирует ответ обработчика в контейнер ответа вызова
+func copyResponse(dst, src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	dstMsg, ok := dst.(proto.Message)
+	srcMsg, ok2 := src.(proto.Message)
+	if !ok || !ok2 {
+		return status.Errorf(codes.Internal, "ответ обработчика должен быть proto.Message, получено %T", src)
+	}
+	if dstMsg.ProtoReflect().Descriptor() != srcMsg.ProtoReflect().Descriptor() {
+		return status.Errorf(codes.Internal, "тип ответа обработчика %T не совпадает с ожидаемым %T", src, dst)
+	}
+	proto.Reset(dstMsg)
+	proto.Merge(dstMsg, srcMsg)
+	return nil
+}
+
+// normalizeMethod приводит имя метода к виду /package.Service/Method
+func normalizeMethod(method string) string {
+	if strings.HasPrefix(method, "/") {
+		return method
+	}
+	return fmt.Sprintf("/%s", method)
+}
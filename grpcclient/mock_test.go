@@ -0,0 +1,209 @@
+package grpcclient
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	mockMethod  = "/pkg.Service/Get"
+	otherMethod = "/pkg.Service/Other"
+)
+
+// fakeT запоминает ошибки проверок вместо завершения теста
+type fakeT struct{ errors []string }
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func checkHealth(ctx context.Context, client *Client, method, service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	resp, err := Invoke[healthpb.HealthCheckResponse](ctx, client, method, &healthpb.HealthCheckRequest{Service: service})
+	return resp.GetStatus(), err
+}
+
+func TestMockScriptSteps(t *testing.T) {
+	client := NewMockClient()
+	client.AddScript(mockMethod,
+		MockStep{Err: status.Error(codes.NotFound, "не найдено")},
+		MockStep{Response: &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}},
+		MockStep{Response: &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}},
+	)
+
+	if _, err := checkHealth(context.Background(), client, mockMethod, ""); Code(err) != codes.NotFound {
+		t.Fatalf("шаг 1: код %v, ожидался NotFound", Code(err))
+	}
+	// После исчерпания сценария повторяется последний шаг
+	want := []healthpb.HealthCheckResponse_ServingStatus{
+		healthpb.HealthCheckResponse_SERVING,
+		healthpb.HealthCheckResponse_NOT_SERVING,
+		healthpb.HealthCheckResponse_NOT_SERVING,
+		healthpb.HealthCheckResponse_NOT_SERVING,
+	}
+	for i, expected := range want {
+		got, err := checkHealth(context.Background(), client, mockMethod, "")
+		if err != nil {
+			t.Fatalf("шаг %d: %v", i+2, err)
+		}
+		if got != expected {
+			t.Fatalf("шаг %d: статус %v, ожидался %v", i+2, got, expected)
+		}
+	}
+	client.AssertNumberOfCalls(t, mockMethod, 5)
+}
+
+func TestMockStepDelayRespectsContext(t *testing.T) {
+	client := NewMockClient()
+	client.AddScript(mockMethod, MockStep{Delay: time.Hour, Response: &healthpb.HealthCheckResponse{}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := checkHealth(ctx, client, mockMethod, "")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("вызов вернулся через %v, ожидался выход по отмене контекста", elapsed)
+	}
+	if Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("код %v, ожидался DeadlineExceeded", Code(err))
+	}
+}
+
+func TestMockHandler(t *testing.T) {
+	client := NewMockClient()
+	client.AddHandler(mockMethod, func(ctx context.Context, req interface{}) (interface{}, error) {
+		if req.(*healthpb.HealthCheckRequest).GetService() == "down" {
+			return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+		}
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+	})
+	client.AddHandler(otherMethod, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+	})
+	client.AddScript(otherMethod, MockStep{Response: &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVICE_UNKNOWN}})
+
+	// Метод без сценария обслуживается обработчиком
+	if got, err := checkHealth(context.Background(), client, mockMethod, "down"); err != nil || got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("обработчик вернул (%v, %v)", got, err)
+	}
+	if got, err := checkHealth(context.Background(), client, "pkg.Service/Get", "up"); err != nil || got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("обработчик метода без / вернул (%v, %v)", got, err)
+	}
+	// Сценарий имеет приоритет над обработчиком
+	if got, err := checkHealth(context.Background(), client, otherMethod, ""); err != nil || got != healthpb.HealthCheckResponse_SERVICE_UNKNOWN {
+		t.Fatalf("метод со сценарием вернул (%v, %v)", got, err)
+	}
+}
+
+func TestMockUnregisteredMethod(t *testing.T) {
+	client := NewMockClient()
+	if _, err := checkHealth(context.Background(), client, mockMethod, ""); Code(err) != codes.Unimplemented {
+		t.Fatalf("код %v, ожидался Unimplemented", Code(err))
+	}
+	client.AssertCalled(t, mockMethod)
+}
+
+func TestMockPassThroughToNetwork(t *testing.T) {
+	server := startCountingServer(t)
+	client, err := NewClient(server.addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.AddHandler(otherMethod, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, err := checkHealth(ctx, client, healthpb.Health_Check_FullMethodName, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != healthpb.HealthCheckResponse_SERVING || server.hits.Load() != 1 {
+		t.Fatalf("метод без обработчика: статус %v, вызовов сервера %d", got, server.hits.Load())
+	}
+	if got, err := checkHealth(ctx, client, otherMethod, ""); err != nil || got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("обработчик вернул (%v, %v)", got, err)
+	}
+	if server.hits.Load() != 1 {
+		t.Fatalf("метод с обработчиком дошел до сервера")
+	}
+	client.AssertNotCalled(t, healthpb.Health_Check_FullMethodName)
+	client.AssertNumberOfCalls(t, otherMethod, 1)
+}
+
+func TestMockCalls(t *testing.T) {
+	client := NewMockClient()
+	client.AddScript(mockMethod, MockStep{Response: &healthpb.HealthCheckResponse{}})
+
+	req := &healthpb.HealthCheckRequest{Service: "users"}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-tenant", "acme")
+	if _, err := Invoke[healthpb.HealthCheckResponse](ctx, client, mockMethod, req); err != nil {
+		t.Fatal(err)
+	}
+	req.Service = "changed"
+
+	calls := client.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("вызовов %d, ожидался 1", len(calls))
+	}
+	call := calls[0]
+	if call.Method != mockMethod {
+		t.Fatalf("метод %q", call.Method)
+	}
+	if got := call.Request.(*healthpb.HealthCheckRequest).GetService(); got != "users" {
+		t.Fatalf("в журнале запрос %q, ожидалась копия на момент вызова", got)
+	}
+	if got := call.Metadata.Get("x-tenant"); len(got) != 1 || got[0] != "acme" {
+		t.Fatalf("метаданные вызова %v", call.Metadata)
+	}
+	if len(client.CallsTo(otherMethod)) != 0 {
+		t.Fatal("CallsTo вернул вызовы другого метода")
+	}
+
+	client.ResetCalls()
+	if len(client.Calls()) != 0 {
+		t.Fatal("журнал не очищен")
+	}
+}
+
+func TestMockAssertions(t *testing.T) {
+	client := NewMockClient()
+	client.AddScript(mockMethod, MockStep{Response: &healthpb.HealthCheckResponse{}})
+	if _, err := checkHealth(context.Background(), client, mockMethod, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	passing := &fakeT{}
+	if !client.AssertCalled(passing, mockMethod) ||
+		!client.AssertNotCalled(passing, otherMethod) ||
+		!client.AssertNumberOfCalls(passing, mockMethod, 1) {
+		t.Fatal("проверка не прошла для корректных ожиданий")
+	}
+	if len(passing.errors) != 0 {
+		t.Fatalf("неожиданные ошибки: %v", passing.errors)
+	}
+
+	failing := &fakeT{}
+	if client.AssertCalled(failing, otherMethod) {
+		t.Fatal("AssertCalled прошел для невызванного метода")
+	}
+	if client.AssertNotCalled(failing, mockMethod) {
+		t.Fatal("AssertNotCalled прошел для вызванного метода")
+	}
+	if client.AssertNumberOfCalls(failing, mockMethod, 2) {
+		t.Fatal("AssertNumberOfCalls прошел для неверного числа вызовов")
+	}
+	if len(failing.errors) != 3 {
+		t.Fatalf("ошибок %d, ожидалось 3: %v", len(failing.errors), failing.errors)
+	}
+}
@@ -0,0 +1,93 @@
+package grpcclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OAuth2Config описывает получение токена по OAuth2 client credentials
+type OAuth2Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// HTTPClient — клиент для запросов к TokenURL; по умолчанию http.DefaultClient
+	HTTPClient *http.Client
+	// RefreshBefore — за сколько до истечения токен запрашивается заново; по умолчанию 1 минута,
+	// но не больше половины срока действия токена. Без expires_in токен действует 1 час.
+	RefreshBefore time.Duration
+}
+
+// oauth2Token — ответ token endpoint
+type oauth2Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// NewOAuth2ClientCredentials создает провайдер, получающий токены от token endpoint
+func NewOAuth2ClientCredentials(cfg OAuth2Config) CredentialsProvider {
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	if cfg.RefreshBefore <= 0 {
+		cfg.RefreshBefore = time.Minute
+	}
+
+	return &tokenCache{
+		refreshBefore: cfg.RefreshBefore,
+		fetch: func(ctx context.Context) (string, time.Time, error) {
+			return fetchOAuth2Token(ctx, cfg)
+		},
+	}
+}
+
+func fetchOAuth2Token(ctx context.Context, cfg OAuth2Config) (string, time.Time, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(cfg.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("ошибка формирования запроса токена: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+
+	resp, err := cfg.HTTPClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("ошибка запроса токена: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("ошибка чтения ответа token endpoint: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("token endpoint вернул %s: %s", resp.Status, body)
+	}
+
+	var token oauth2Token
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", time.Time{}, fmt.Errorf("ошибка разбора ответа token endpoint: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", time.Time{}, fmt.Errorf("token endpoint не вернул access_token")
+	}
+
+	// Без expires_in tokenCache использует срок действия по умолчанию (defaultTokenLifetime)
+	var expiry time.Time
+	if token.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return token.AccessToken, expiry, nil
+}
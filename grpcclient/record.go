@@ -0,0 +1,390 @@
+package grpcclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// RecordFormat — формат файла записанных вызовов
+type RecordFormat int
+
+const (
+	// RecordJSONLines — по одному вызову в строке, сообщения в protojson
+	RecordJSONLines RecordFormat = iota
+	// RecordBinary — вызовы в бинарном protobuf, каждая запись с префиксом длины (varint)
+	RecordBinary
+)
+
+// Поля записи вызова в формате RecordBinary
+const (
+	recordMethodField   protowire.Number = 1
+	recordMetadataField protowire.Number = 2
+	recordRequestField  protowire.Number = 3
+	recordResponseField protowire.Number = 4
+	recordStatusField   protowire.Number = 5
+
+	metadataKeyField   protowire.Number = 1
+	metadataValueField protowire.Number = 2
+)
+
+// skippedMetadata — метаданные, которые не записываются в файл
+var skippedMetadata = []string{"authorization"}
+
+// Interaction — записанный вызов: запрос, ответ и итоговый статус
+type Interaction struct {
+	Method   string
+	Metadata metadata.MD
+	Status   *status.Status
+
+	format   RecordFormat
+	request  []byte
+	response []byte
+}
+
+// UnmarshalRequest декодирует записанный запрос в m
+func (i *Interaction) UnmarshalRequest(m proto.Message) error {
+	return i.unmarshal(i.request, m)
+}
+
+// UnmarshalResponse декодирует записанный ответ в m
+func (i *Interaction) UnmarshalResponse(m proto.Message) error {
+	return i.unmarshal(i.response, m)
+}
+
+func (i *Interaction) unmarshal(data []byte, m proto.Message) error {
+	if len(data) == 0 {
+		proto.Reset(m)
+		return nil
+	}
+	if i.format == RecordJSONLines {
+		return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, m)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// jsonInteraction — запись вызова в формате RecordJSONLines
+type jsonInteraction struct {
+	Method   string              `json:"method"`
+	Metadata map[string][]string `json:"metadata,omitempty"`
+	Request  json.RawMessage     `json:"request,omitempty"`
+	Response json.RawMessage     `json:"response,omitempty"`
+	Status   json.RawMessage     `json:"status,omitempty"`
+}
+
+// Recorder записывает унарные вызовы клиента в файл для последующего воспроизведения
+type Recorder struct {
+	format RecordFormat
+
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+	// err — первая ошибка записи, возвращается из Close
+	err error
+}
+
+// NewRecorder создает файл path и записывает в него вызовы в формате format
+func NewRecorder(path string, format RecordFormat) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания файла записи: %w", err)
+	}
+	return &Recorder{format: format, file: file, w: bufio.NewWriter(file)}, nil
+}
+
+// WithRecorder включает запись сетевых унарных вызовов клиента.
+// Каждый вызов записывается один раз, после всех повторов: метод, метаданные (кроме authorization),
+// запрос, итоговый ответ и статус.
+func WithRecorder(r *Recorder) ClientOption {
+	return func(c *Client) {
+		c.recorder = r
+	}
+}
+
+// Close дописывает буферизованные записи и закрывает файл.
+// Возвращает первую ошибку, возникшую при записи вызовов.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.w.Flush()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	if r.err != nil {
+		return r.err
+	}
+	return err
+}
+
+// record записывает вызов. Ошибки записи не влияют на результат вызова
+// и возвращаются из Close.
+func (r *Recorder) record(ctx context.Context, method string, req, resp interface{}, callErr error) {
+	if r == nil {
+		return
+	}
+	reqMsg, ok := req.(proto.Message)
+	if !ok {
+		return
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	for _, key := range skippedMetadata {
+		delete(md, key)
+	}
+	st := status.Convert(callErr)
+
+	var respMsg proto.Message
+	if callErr == nil {
+		respMsg, _ = resp.(proto.Message)
+	}
+
+	var data []byte
+	var err error
+	if r.format == RecordJSONLines {
+		data, err = marshalJSONInteraction(normalizeMethod(method), md, reqMsg, respMsg, st)
+	} else {
+		data, err = marshalBinaryInteraction(normalizeMethod(method), md, reqMsg, respMsg, st)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		_, err = r.w.Write(data)
+	}
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("ошибка записи вызова %s: %w", method, err)
+	}
+}
+
+func marshalJSONInteraction(method string, md metadata.MD, req, resp proto.Message, st *status.Status) ([]byte, error) {
+	rec := jsonInteraction{Method: method, Metadata: md}
+
+	var err error
+	if rec.Request, err = protojson.Marshal(req); err != nil {
+		return nil, err
+	}
+	if resp != nil {
+		if rec.Response, err = protojson.Marshal(resp); err != nil {
+			return nil, err
+		}
+	}
+	if st.Code() != codes.OK {
+		// Детали неизвестных типов не переводятся в JSON: сохраняем статус без них
+		if rec.Status, err = protojson.Marshal(st.Proto()); err != nil {
+			rec.Status, err = protojson.Marshal(&spb.Status{Code: int32(st.Code()), Message: st.Message()})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+func marshalBinaryInteraction(method string, md metadata.MD, req, resp proto.Message, st *status.Status) ([]byte, error) {
+	opts := proto.MarshalOptions{Deterministic: true}
+
+	var rec []byte
+	rec = protowire.AppendTag(rec, recordMethodField, protowire.BytesType)
+	rec = protowire.AppendString(rec, method)
+	for _, key := range slices.Sorted(maps.Keys(md)) {
+		values := md[key]
+		var entry []byte
+		entry = protowire.AppendTag(entry, metadataKeyField, protowire.BytesType)
+		entry = protowire.AppendString(entry, key)
+		for _, value := range values {
+			entry = protowire.AppendTag(entry, metadataValueField, protowire.BytesType)
+			entry = protowire.AppendString(entry, value)
+		}
+		rec = protowire.AppendTag(rec, recordMetadataField, protowire.BytesType)
+		rec = protowire.AppendBytes(rec, entry)
+	}
+
+	reqData, err := opts.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	rec = protowire.AppendTag(rec, recordRequestField, protowire.BytesType)
+	rec = protowire.AppendBytes(rec, reqData)
+
+	if resp != nil {
+		respData, err := opts.Marshal(resp)
+		if err != nil {
+			return nil, err
+		}
+		rec = protowire.AppendTag(rec, recordResponseField, protowire.BytesType)
+		rec = protowire.AppendBytes(rec, respData)
+	}
+	if st.Code() != codes.OK {
+		stData, err := opts.Marshal(st.Proto())
+		if err != nil {
+			return nil, err
+		}
+		rec = protowire.AppendTag(rec, recordStatusField, protowire.BytesType)
+		rec = protowire.AppendBytes(rec, stData)
+	}
+
+	return protowire.AppendBytes(nil, rec), nil
+}
+
+// LoadInteractions читает записанные вызовы из файла
+func LoadInteractions(path string, format RecordFormat) ([]*Interaction, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла записи: %w", err)
+	}
+	defer file.Close()
+
+	if format == RecordJSONLines {
+		return readJSONInteractions(file)
+	}
+	return readBinaryInteractions(file)
+}
+
+func readJSONInteractions(r io.Reader) ([]*Interaction, error) {
+	var interactions []*Interaction
+	dec := json.NewDecoder(r)
+	for {
+		var rec jsonInteraction
+		if err := dec.Decode(&rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				return interactions, nil
+			}
+			return nil, fmt.Errorf("ошибка чтения записи %d: %w", len(interactions)+1, err)
+		}
+
+		i := &Interaction{
+			Method:   normalizeMethod(rec.Method),
+			Metadata: metadata.MD(rec.Metadata),
+			Status:   status.New(codes.OK, ""),
+			format:   RecordJSONLines,
+			request:  rec.Request,
+			response: rec.Response,
+		}
+		if len(rec.Status) > 0 {
+			st := &spb.Status{}
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(rec.Status, st); err != nil {
+				return nil, fmt.Errorf("ошибка чтения статуса записи %d: %w", len(interactions)+1, err)
+			}
+			i.Status = status.FromProto(st)
+		}
+		interactions = append(interactions, i)
+	}
+}
+
+func readBinaryInteractions(r io.Reader) ([]*Interaction, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var interactions []*Interaction
+	for len(data) > 0 {
+		rec, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return nil, fmt.Errorf("ошибка чтения записи %d: %w", len(interactions)+1, protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		i, err := parseBinaryInteraction(rec)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения записи %d: %w", len(interactions)+1, err)
+		}
+		interactions = append(interactions, i)
+	}
+	return interactions, nil
+}
+
+func parseBinaryInteraction(rec []byte) (*Interaction, error) {
+	i := &Interaction{
+		Metadata: metadata.MD{},
+		Status:   status.New(codes.OK, ""),
+		format:   RecordBinary,
+	}
+
+	err := consumeFields(rec, func(num protowire.Number, value []byte) error {
+		switch num {
+		case recordMethodField:
+			i.Method = normalizeMethod(string(value))
+		case recordMetadataField:
+			var key string
+			var values []string
+			err := consumeFields(value, func(num protowire.Number, value []byte) error {
+				switch num {
+				case metadataKeyField:
+					key = string(value)
+				case metadataValueField:
+					values = append(values, string(value))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			i.Metadata.Append(key, values...)
+		case recordRequestField:
+			i.request = value
+		case recordResponseField:
+			i.response = value
+		case recordStatusField:
+			st := &spb.Status{}
+			if err := proto.Unmarshal(value, st); err != nil {
+				return err
+			}
+			i.Status = status.FromProto(st)
+		}
+		return nil
+	})
+	return i, err
+}
+
+// consumeFields разбирает поля сообщения с типом BytesType; поля других типов пропускаются
+func consumeFields(data []byte, field func(num protowire.Number, value []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if err := field(num, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package grpcclient
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// startHealthServer запускает health-сервер, отвечающий Unavailable на первые failures вызовов
+func startHealthServer(t *testing.T, failures int64) string {
+	t.Helper()
+	var calls atomic.Int64
+	srv := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if calls.Add(1) <= failures {
+			return nil, status.Error(codes.Unavailable, "недоступен")
+		}
+		return handler(ctx, req)
+	}))
+	hs := health.NewServer()
+	hs.SetServingStatus("up", healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("down", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+// recordHealth записывает вызовы Check для сервисов services в файл
+func recordHealth(t *testing.T, addr string, format RecordFormat, services ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "calls")
+	recorder, err := NewRecorder(path, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(addr, WithRecorder(recorder), WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    3,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+		InitialBackoff: time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-tenant", "acme", authorizationHeader, "Bearer secret")
+	for _, service := range services {
+		_, _ = checkHealth(ctx, client, healthpb.Health_Check_FullMethodName, service)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRecordReplayRoundTrip(t *testing.T) {
+	formats := map[string]RecordFormat{"jsonl": RecordJSONLines, "binary": RecordBinary}
+	for name, format := range formats {
+		t.Run(name, func(t *testing.T) {
+			path := recordHealth(t, startHealthServer(t, 0), format, "up", "down", "missing")
+
+			interactions, err := LoadInteractions(path, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(interactions) != 3 {
+				t.Fatalf("записей %d, ожидалось 3", len(interactions))
+			}
+			for _, i := range interactions {
+				if i.Method != healthpb.Health_Check_FullMethodName {
+					t.Fatalf("метод %q", i.Method)
+				}
+				if got := i.Metadata.Get("x-tenant"); len(got) != 1 || got[0] != "acme" {
+					t.Fatalf("метаданные записи %v", i.Metadata)
+				}
+				if len(i.Metadata.Get(authorizationHeader)) != 0 {
+					t.Fatal("authorization не должен записываться")
+				}
+			}
+			req := &healthpb.HealthCheckRequest{}
+			if err := interactions[1].UnmarshalRequest(req); err != nil || req.GetService() != "down" {
+				t.Fatalf("запрос второй записи (%v, %v)", req, err)
+			}
+			if code := interactions[2].Status.Code(); code != codes.NotFound {
+				t.Fatalf("статус третьей записи %v, ожидался NotFound", code)
+			}
+
+			replayer, err := NewReplayer(path, format, MatchExact())
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := NewMockClient(WithReplay(replayer))
+
+			// MatchExact находит запись по запросу независимо от порядка вызовов
+			if got, err := checkHealth(context.Background(), client, healthpb.Health_Check_FullMethodName, "down"); err != nil || got != healthpb.HealthCheckResponse_NOT_SERVING {
+				t.Fatalf("down: (%v, %v)", got, err)
+			}
+			if _, err := checkHealth(context.Background(), client, healthpb.Health_Check_FullMethodName, "missing"); Code(err) != codes.NotFound {
+				t.Fatalf("missing: код %v, ожидался NotFound", Code(err))
+			}
+			if got, err := checkHealth(context.Background(), client, healthpb.Health_Check_FullMethodName, "up"); err != nil || got != healthpb.HealthCheckResponse_SERVING {
+				t.Fatalf("up: (%v, %v)", got, err)
+			}
+			if n := replayer.Remaining(); n != 0 {
+				t.Fatalf("неиспользованных записей %d", n)
+			}
+			if !replayer.AssertAllMatched(t) {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestReplayUnmatchedCall(t *testing.T) {
+	path := recordHealth(t, startHealthServer(t, 0), RecordJSONLines, "up")
+	replayer, err := NewReplayer(path, RecordJSONLines, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewMockClient(WithReplay(replayer))
+
+	if _, err := checkHealth(context.Background(), client, healthpb.Health_Check_FullMethodName, "other"); Code(err) != codes.Unimplemented {
+		t.Fatalf("код %v, ожидался Unimplemented", Code(err))
+	}
+	if _, err := checkHealth(context.Background(), client, healthpb.Health_Check_FullMethodName, "up"); err != nil {
+		t.Fatal(err)
+	}
+	// Каждая запись используется один раз
+	if _, err := checkHealth(context.Background(), client, healthpb.Health_Check_FullMethodName, "up"); Code(err) != codes.Unimplemented {
+		t.Fatalf("повторный вызов: код %v, ожидался Unimplemented", Code(err))
+	}
+
+	unmatched := replayer.Unmatched()
+	if len(unmatched) != 2 || unmatched[0].Request.(*healthpb.HealthCheckRequest).GetService() != "other" {
+		t.Fatalf("вызовы без записи: %v", unmatched)
+	}
+	ft := &fakeT{}
+	if replayer.AssertAllMatched(ft) || len(ft.errors) != 1 {
+		t.Fatalf("AssertAllMatched должен был завершиться ошибкой: %v", ft.errors)
+	}
+}
+
+func TestReplayMatchMethod(t *testing.T) {
+	path := recordHealth(t, startHealthServer(t, 0), RecordBinary, "up", "down")
+	replayer, err := NewReplayer(path, RecordBinary, MatchMethod())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewMockClient(WithReplay(replayer))
+
+	// Запрос не сравнивается: ответы выдаются в порядке записи
+	want := []healthpb.HealthCheckResponse_ServingStatus{healthpb.HealthCheckResponse_SERVING, healthpb.HealthCheckResponse_NOT_SERVING}
+	for i, expected := range want {
+		got, err := checkHealth(context.Background(), client, healthpb.Health_Check_FullMethodName, "other")
+		if err != nil || got != expected {
+			t.Fatalf("вызов %d: (%v, %v), ожидался %v", i+1, got, err, expected)
+		}
+	}
+	if _, err := checkHealth(context.Background(), client, healthpb.Health_Watch_FullMethodName, ""); Code(err) != codes.Unimplemented {
+		t.Fatalf("другой метод: код %v, ожидался Unimplemented", Code(err))
+	}
+}
+
+func TestRecordOncePerLogicalCall(t *testing.T) {
+	// Две первые попытки завершаются Unavailable, третья — успешно
+	path := recordHealth(t, startHealthServer(t, 2), RecordBinary, "up")
+
+	interactions, err := LoadInteractions(path, RecordBinary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interactions) != 1 {
+		t.Fatalf("записей %d, ожидалась 1 на логический вызов", len(interactions))
+	}
+	if code := interactions[0].Status.Code(); code != codes.OK {
+		t.Fatalf("записан статус %v, ожидался итоговый OK", code)
+	}
+	resp := &healthpb.HealthCheckResponse{}
+	if err := interactions[0].UnmarshalResponse(resp); err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("записан ответ (%v, %v)", resp, err)
+	}
+}
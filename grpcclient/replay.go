@@ -0,0 +1,151 @@
+package grpcclient
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Matcher проверяет, соответствует ли записанный вызов текущему
+type Matcher func(recorded *Interaction, method string, req proto.Message, md metadata.MD) bool
+
+// MatchExact сопоставляет вызовы по методу и содержимому запроса.
+// Метаданные не сравниваются: идентификаторы запросов и трассировки меняются между запусками.
+func MatchExact() Matcher {
+	return func(recorded *Interaction, method string, req proto.Message, _ metadata.MD) bool {
+		if recorded.Method != method {
+			return false
+		}
+		want := req.ProtoReflect().New().Interface()
+		if err := recorded.UnmarshalRequest(want); err != nil {
+			return false
+		}
+		return proto.Equal(want, req)
+	}
+}
+
+// MatchMethod сопоставляет вызовы только по методу, в порядке записи
+func MatchMethod() Matcher {
+	return func(recorded *Interaction, method string, _ proto.Message, _ metadata.MD) bool {
+		return recorded.Method == method
+	}
+}
+
+// Replayer воспроизводит записанные вызовы вместо обращения к сети.
+// Каждая запись используется один раз; из подходящих выбирается первая неиспользованная,
+// поэтому повторяющиеся вызовы получают ответы в порядке записи.
+type Replayer struct {
+	matcher Matcher
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+	unmatched    []Call
+}
+
+// NewReplayer загружает записанные вызовы из файла.
+// Если matcher не задан, используется MatchExact.
+func NewReplayer(path string, format RecordFormat, matcher Matcher) (*Replayer, error) {
+	interactions, err := LoadInteractions(path, format)
+	if err != nil {
+		return nil, err
+	}
+	if matcher == nil {
+		matcher = MatchExact()
+	}
+	return &Replayer{
+		matcher:      matcher,
+		interactions: interactions,
+		used:         make([]bool, len(interactions)),
+	}, nil
+}
+
+// WithReplay включает воспроизведение унарных вызовов клиента из записи:
+//
+//	replayer, err := grpcclient.NewReplayer("testdata/users.jsonl", grpcclient.RecordJSONLines, nil)
+//	client := grpcclient.NewMockClient(grpcclient.WithReplay(replayer))
+//
+// Вызов без подходящей записи завершается с кодом Unimplemented.
+func WithReplay(r *Replayer) ClientOption {
+	return func(c *Client) {
+		c.replayer = r
+	}
+}
+
+// Unmatched возвращает вызовы, для которых не нашлось записи
+func (r *Replayer) Unmatched() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.unmatched...)
+}
+
+// Remaining возвращает число неиспользованных записей
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remaining := 0
+	for _, used := range r.used {
+		if !used {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+// AssertAllMatched проверяет, что все вызовы нашли запись
+func (r *Replayer) AssertAllMatched(t TestingT) bool {
+	t.Helper()
+	if unmatched := r.Unmatched(); len(unmatched) > 0 {
+		t.Errorf("для %d вызовов нет записи, первый: %s", len(unmatched), unmatched[0].Method)
+		return false
+	}
+	return true
+}
+
+// replay отвечает на вызов записанным ответом или статусом
+func (r *Replayer) replay(ctx context.Context, method string, req, resp interface{}) error {
+	method = normalizeMethod(method)
+	md, _ := metadata.FromOutgoingContext(ctx)
+
+	reqMsg, ok := req.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "воспроизведение поддерживает только proto.Message, получено %T", req)
+	}
+
+	recorded := r.take(method, reqMsg, md)
+	if recorded == nil {
+		return status.Errorf(codes.Unimplemented, "нет записи для вызова %s", method)
+	}
+	if recorded.Status.Code() != codes.OK {
+		return recorded.Status.Err()
+	}
+
+	respMsg, ok := resp.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "воспроизведение поддерживает только proto.Message, получено %T", resp)
+	}
+	if err := recorded.UnmarshalResponse(respMsg); err != nil {
+		return status.Errorf(codes.Internal, "ошибка чтения записанного ответа %s: %v", method, err)
+	}
+	return nil
+}
+
+// take находит первую неиспользованную подходящую запись и помечает ее использованной
+func (r *Replayer) take(method string, req proto.Message, md metadata.MD) *Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, recorded := range r.interactions {
+		if !r.used[i] && r.matcher(recorded, method, req, md) {
+			r.used[i] = true
+			return recorded
+		}
+	}
+	r.unmatched = append(r.unmatched, Call{Method: method, Request: proto.Clone(req), Metadata: md.Copy()})
+	return nil
+}
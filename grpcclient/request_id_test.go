@@ -0,0 +1,53 @@
+package grpcclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arrowwhi/go-utils/grpcclient/interceptors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestRequestIDSharedByRetries(t *testing.T) {
+	const method = "/test.Service/Get"
+	policy := DefaultRetryPolicy(2)
+	policy.InitialBackoff = time.Millisecond
+	policy.Jitter = false
+
+	client := NewMockClient(WithRequestID(), WithRetryPolicy(policy))
+	client.AddScript(method,
+		MockStep{Err: status.Error(codes.Unavailable, "недоступен")},
+		MockStep{Err: status.Error(codes.Unavailable, "недоступен")},
+		MockStep{Response: wrapperspb.String("ok")},
+	)
+
+	if err := client.invoke(context.Background(), method, wrapperspb.String("req"), &wrapperspb.StringValue{}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := client.CallsTo(method)
+	if len(calls) != 3 {
+		t.Fatalf("выполнено %d попыток, ожидалось 3", len(calls))
+	}
+	id := calls[0].Metadata.Get(interceptors.RequestIDHeader)
+	if len(id) != 1 || id[0] == "" {
+		t.Fatalf("первая попытка без x-request-id: %v", calls[0].Metadata)
+	}
+	for i, call := range calls[1:] {
+		if got := call.Metadata.Get(interceptors.RequestIDHeader); len(got) != 1 || got[0] != id[0] {
+			t.Fatalf("попытка %d передала x-request-id %v, ожидался %s", i+2, got, id[0])
+		}
+	}
+
+	// Следующий вызов получает новый идентификатор
+	client.ResetCalls()
+	if err := client.invoke(context.Background(), method, wrapperspb.String("req"), &wrapperspb.StringValue{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := client.CallsTo(method)[0].Metadata.Get(interceptors.RequestIDHeader); len(got) != 1 || got[0] == id[0] {
+		t.Fatalf("новый вызов получил x-request-id %v, ожидался новый идентификатор", got)
+	}
+}
@@ -0,0 +1,218 @@
+package grpcclient
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// resolverScheme — схема цели, по которой соединение использует Resolver клиента
+const resolverScheme = "grpcclient"
+
+// defaultResolveInterval — период повторного разрешения адресов по умолчанию
+const defaultResolveInterval = 30 * time.Second
+
+// Endpoint описывает адрес экземпляра сервиса
+type Endpoint struct {
+	// Addr — адрес в формате host:port
+	Addr string
+	// Weight — вес экземпляра для политики WeightedPolicy; 0 означает вес 1
+	Weight uint32
+}
+
+// Resolver поставляет актуальный список адресов сервиса.
+// Resolve вызывается периодически, а также при обрыве соединений.
+type Resolver interface {
+	Resolve(ctx context.Context) ([]Endpoint, error)
+}
+
+// ResolverFunc позволяет использовать функцию как Resolver
+type ResolverFunc func(ctx context.Context) ([]Endpoint, error)
+
+func (f ResolverFunc) Resolve(ctx context.Context) ([]Endpoint, error) {
+	return f(ctx)
+}
+
+// WithResolver подключает Resolver для поиска адресов сервиса.
+// Адреса перечитываются каждые refreshInterval (по умолчанию 30 секунд) и при обрыве соединений;
+// target в NewClient в этом случае используется только как имя сервиса.
+func WithResolver(r Resolver, refreshInterval time.Duration) ClientOption {
+	return func(c *Client) {
+		if refreshInterval <= 0 {
+			refreshInterval = defaultResolveInterval
+		}
+		c.resolver = &resolverBuilder{resolver: r, interval: refreshInterval}
+	}
+}
+
+// WithStaticAddresses задает фиксированный список адресов сервиса
+func WithStaticAddresses(addrs ...string) ClientOption {
+	return WithResolver(StaticResolver(addrs...), 0)
+}
+
+// StaticResolver возвращает Resolver с фиксированным списком адресов
+func StaticResolver(addrs ...string) Resolver {
+	endpoints := make([]Endpoint, 0, len(addrs))
+	for _, addr := range addrs {
+		endpoints = append(endpoints, Endpoint{Addr: addr})
+	}
+	return ResolverFunc(func(context.Context) ([]Endpoint, error) {
+		return endpoints, nil
+	})
+}
+
+// FileResolver читает адреса из файла: по одному "host:port [weight]" на строку,
+// пустые строки и строки, начинающиеся с #, пропускаются
+func FileResolver(path string) Resolver {
+	return ResolverFunc(func(context.Context) ([]Endpoint, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения файла адресов: %w", err)
+		}
+		defer f.Close()
+
+		var endpoints []Endpoint
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+				continue
+			}
+			endpoint := Endpoint{Addr: fields[0]}
+			if len(fields) > 1 {
+				weight, err := strconv.ParseUint(fields[1], 10, 32)
+				if err != nil {
+					return nil, fmt.Errorf("некорректный вес адреса %s: %w", fields[0], err)
+				}
+				endpoint.Weight = uint32(weight)
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("ошибка чтения файла адресов: %w", err)
+		}
+		return endpoints, nil
+	})
+}
+
+// DNSResolver разрешает A/AAAA записи host и использует для всех адресов порт port
+func DNSResolver(host, port string) Resolver {
+	return ResolverFunc(func(ctx context.Context) ([]Endpoint, error) {
+		ips, err := net.DefaultResolver.LookupHost(ctx, host)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка разрешения %s: %w", host, err)
+		}
+		endpoints := make([]Endpoint, 0, len(ips))
+		for _, ip := range ips {
+			endpoints = append(endpoints, Endpoint{Addr: net.JoinHostPort(ip, port)})
+		}
+		return endpoints, nil
+	})
+}
+
+// SRVResolver разрешает SRV запись _service._proto.name; вес берется из записи
+func SRVResolver(service, proto, name string) Resolver {
+	return ResolverFunc(func(ctx context.Context) ([]Endpoint, error) {
+		_, records, err := net.DefaultResolver.LookupSRV(ctx, service, proto, name)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка разрешения SRV %s: %w", name, err)
+		}
+		endpoints := make([]Endpoint, 0, len(records))
+		for _, record := range records {
+			endpoints = append(endpoints, Endpoint{
+				Addr:   net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port))),
+				Weight: uint32(record.Weight),
+			})
+		}
+		return endpoints, nil
+	})
+}
+
+// resolverBuilder адаптирует Resolver к resolver.Builder из gRPC
+type resolverBuilder struct {
+	resolver Resolver
+	interval time.Duration
+}
+
+func (b *resolverBuilder) Scheme() string {
+	return resolverScheme
+}
+
+func (b *resolverBuilder) Build(_ resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &resolverWatcher{
+		builder:    b,
+		cc:         cc,
+		ctx:        ctx,
+		cancel:     cancel,
+		resolveNow: make(chan struct{}, 1),
+	}
+	w.wg.Add(1)
+	go w.run()
+	return w, nil
+}
+
+// resolverWatcher периодически опрашивает Resolver и передает адреса соединению
+type resolverWatcher struct {
+	builder    *resolverBuilder
+	cc         resolver.ClientConn
+	ctx        context.Context
+	cancel     context.CancelFunc
+	resolveNow chan struct{}
+	wg         sync.WaitGroup
+}
+
+func (w *resolverWatcher) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.builder.interval)
+	defer ticker.Stop()
+
+	for {
+		w.update()
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-w.resolveNow:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *resolverWatcher) update() {
+	endpoints, err := w.builder.resolver.Resolve(w.ctx)
+	if err != nil {
+		w.cc.ReportError(err)
+		return
+	}
+	if len(endpoints) == 0 {
+		w.cc.ReportError(fmt.Errorf("resolver не вернул ни одного адреса"))
+		return
+	}
+
+	addrs := make([]resolver.Address, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		addrs = append(addrs, withWeight(resolver.Address{Addr: endpoint.Addr}, endpoint.Weight))
+	}
+	_ = w.cc.UpdateState(resolver.State{Addresses: addrs})
+}
+
+func (w *resolverWatcher) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case w.resolveNow <- struct{}{}:
+	default:
+	}
+}
+
+func (w *resolverWatcher) Close() {
+	w.cancel()
+	w.wg.Wait()
+}
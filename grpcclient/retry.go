@@ -0,0 +1,166 @@
+package grpcclient
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// pushbackTrailer — трейлер, которым сервер сообщает, через сколько миллисекунд можно повторить запрос
+const pushbackTrailer = "grpc-retry-pushback-ms"
+
+// RetryPolicy описывает политику повторных попыток вызова
+type RetryPolicy struct {
+	// MaxAttempts — общее число попыток, включая первую
+	MaxAttempts int
+	// RetryableCodes — коды, при которых запрос повторяется
+	RetryableCodes []codes.Code
+	// InitialBackoff и MaxBackoff ограничивают паузу между попытками
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// BackoffMultiplier — множитель паузы для каждой следующей попытки
+	BackoffMultiplier float64
+	// Jitter включает full jitter: пауза выбирается случайно в диапазоне [0, backoff)
+	Jitter bool
+	// PerAttemptTimeout — таймаут одной попытки; 0 — таймаут клиента
+	PerAttemptTimeout time.Duration
+	// Timeout — общий дедлайн на все попытки; 0 — только дедлайн контекста вызывающего
+	Timeout time.Duration
+	// Budget ограничивает долю повторов; nil — без ограничения
+	Budget *RetryBudget
+}
+
+// DefaultRetryPolicy возвращает политику по умолчанию с заданным числом повторов
+func DefaultRetryPolicy(maxRetries int) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:       maxRetries + 1,
+		RetryableCodes:    []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted},
+		InitialBackoff:    time.Second,
+		MaxBackoff:        30 * time.Second,
+		BackoffMultiplier: 2,
+		Jitter:            true,
+	}
+}
+
+// WithRetryPolicy устанавливает политику повторных попыток для всех методов
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = &policy
+	}
+}
+
+// WithMethodRetryPolicy устанавливает политику повторных попыток для конкретного метода
+func WithMethodRetryPolicy(method string, policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.methodRetryPolicies[method] = policy
+	}
+}
+
+// retryPolicyFor возвращает политику для метода
+func (c *Client) retryPolicyFor(method string) RetryPolicy {
+	if policy, ok := c.methodRetryPolicies[method]; ok {
+		return policy
+	}
+	if c.retryPolicy != nil {
+		return *c.retryPolicy
+	}
+	return DefaultRetryPolicy(c.maxRetries)
+}
+
+// retryable проверяет, можно ли повторить запрос при данном коде
+func (p RetryPolicy) retryable(code codes.Code) bool {
+	return slices.Contains(p.RetryableCodes, code)
+}
+
+// backoff вычисляет паузу перед повторной попыткой с номером retry (начиная с 0)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := p.BackoffMultiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(retry))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter && backoff >= 1 {
+		return time.Duration(rand.Int64N(int64(backoff)))
+	}
+	return time.Duration(backoff)
+}
+
+// retryDelay определяет паузу перед повтором с учетом трейлера grpc-retry-pushback-ms.
+// Возвращает false, если сервер запретил повтор.
+func (p RetryPolicy) retryDelay(retry int, trailer metadata.MD) (time.Duration, bool) {
+	values := trailer.Get(pushbackTrailer)
+	if len(values) == 0 {
+		return p.backoff(retry), true
+	}
+
+	ms, err := strconv.Atoi(values[0])
+	if err != nil || ms < 0 {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// sleepContext ожидает d или отмены контекста
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RetryBudget ограничивает число повторов по алгоритму token bucket, как retry throttling в gRPC:
+// каждая неудачная попытка забирает один токен, каждая успешная возвращает tokenRatio токенов.
+// Повторы разрешены, пока токенов больше половины от maxTokens.
+type RetryBudget struct {
+	mu         sync.Mutex
+	tokens     float64
+	maxTokens  float64
+	tokenRatio float64
+}
+
+// NewRetryBudget создает бюджет повторов
+func NewRetryBudget(maxTokens, tokenRatio float64) *RetryBudget {
+	return &RetryBudget{
+		tokens:     maxTokens,
+		maxTokens:  maxTokens,
+		tokenRatio: tokenRatio,
+	}
+}
+
+// onSuccess возвращает токены в бюджет после успешной попытки
+func (b *RetryBudget) onSuccess() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.tokens+b.tokenRatio, b.maxTokens)
+}
+
+// onFailure забирает токен после неудачной попытки и сообщает, разрешен ли повтор
+func (b *RetryBudget) onFailure() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Max(b.tokens-1, 0)
+	return b.tokens > b.maxTokens/2
+}
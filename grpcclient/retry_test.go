@@ -0,0 +1,173 @@
+package grpcclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const retryMethod = "/pkg.Service/Retry"
+
+func TestRetryDelayPushback(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 10 * time.Millisecond, BackoffMultiplier: 2}
+
+	tests := []struct {
+		name    string
+		trailer metadata.MD
+		delay   time.Duration
+		retry   bool
+	}{
+		{"без трейлера", nil, 20 * time.Millisecond, true},
+		{"корректное значение", metadata.Pairs(pushbackTrailer, "250"), 250 * time.Millisecond, true},
+		{"ноль", metadata.Pairs(pushbackTrailer, "0"), 0, true},
+		{"отрицательное значение", metadata.Pairs(pushbackTrailer, "-1"), 0, false},
+		{"некорректное значение", metadata.Pairs(pushbackTrailer, "abc"), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, retry := policy.retryDelay(1, tt.trailer)
+			if delay != tt.delay || retry != tt.retry {
+				t.Fatalf("retryDelay = (%v, %v), ожидалось (%v, %v)", delay, retry, tt.delay, tt.retry)
+			}
+		})
+	}
+}
+
+func TestBackoffCappedAtMaxBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff:    10 * time.Millisecond,
+		MaxBackoff:        50 * time.Millisecond,
+		BackoffMultiplier: 2,
+	}
+	want := []time.Duration{10, 20, 40, 50, 50, 50}
+	for retry, ms := range want {
+		if got := policy.backoff(retry); got != ms*time.Millisecond {
+			t.Fatalf("backoff(%d) = %v, ожидалось %v", retry, got, ms*time.Millisecond)
+		}
+	}
+
+	policy.Jitter = true
+	for retry := 0; retry < 10; retry++ {
+		if got := policy.backoff(retry); got < 0 || got >= policy.MaxBackoff {
+			t.Fatalf("backoff(%d) с jitter = %v, ожидалось в [0, %v)", retry, got, policy.MaxBackoff)
+		}
+	}
+}
+
+func TestRetryUntilSuccess(t *testing.T) {
+	client := NewMockClient(WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    3,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+	}))
+	client.AddScript(retryMethod,
+		MockStep{Err: status.Error(codes.Unavailable, "недоступен")},
+		MockStep{Err: status.Error(codes.Unavailable, "недоступен")},
+		MockStep{Response: &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}},
+	)
+
+	resp, err := Invoke[healthpb.HealthCheckResponse](context.Background(), client, retryMethod, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("статус %v", resp.GetStatus())
+	}
+	client.AssertNumberOfCalls(t, retryMethod, 3)
+}
+
+func TestRetryStopsOnNonRetryableCode(t *testing.T) {
+	client := NewMockClient(WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    5,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+	}))
+	client.AddScript(retryMethod,
+		MockStep{Err: status.Error(codes.InvalidArgument, "некорректный запрос")},
+		MockStep{Response: &healthpb.HealthCheckResponse{}},
+	)
+
+	_, err := Invoke[healthpb.HealthCheckResponse](context.Background(), client, retryMethod, &healthpb.HealthCheckRequest{})
+	if Code(err) != codes.InvalidArgument {
+		t.Fatalf("код %v, ожидался InvalidArgument", Code(err))
+	}
+	client.AssertNumberOfCalls(t, retryMethod, 1)
+}
+
+func TestRetryBudgetExhausted(t *testing.T) {
+	// Первая же неудача опускает токены до половины максимума — повтор подавлен
+	client := NewMockClient(WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    5,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+		Budget:         NewRetryBudget(2, 0.1),
+	}))
+	client.AddScript(retryMethod,
+		MockStep{Err: status.Error(codes.Unavailable, "недоступен")},
+		MockStep{Response: &healthpb.HealthCheckResponse{}},
+	)
+
+	_, err := Invoke[healthpb.HealthCheckResponse](context.Background(), client, retryMethod, &healthpb.HealthCheckRequest{})
+	if Code(err) != codes.Unavailable {
+		t.Fatalf("код %v, ожидался Unavailable", Code(err))
+	}
+	client.AssertNumberOfCalls(t, retryMethod, 1)
+}
+
+func TestRetryBudgetTokens(t *testing.T) {
+	budget := NewRetryBudget(4, 0.5)
+	if !budget.onFailure() {
+		t.Fatal("3 из 4 токенов: повтор должен быть разрешен")
+	}
+	if budget.onFailure() {
+		t.Fatal("2 из 4 токенов: повтор должен быть запрещен")
+	}
+	for i := 0; i < 4; i++ {
+		budget.onSuccess()
+	}
+	if !budget.onFailure() {
+		t.Fatal("после успешных вызовов повтор должен быть снова разрешен")
+	}
+
+	for i := 0; i < 100; i++ {
+		budget.onSuccess()
+	}
+	budget.mu.Lock()
+	tokens := budget.tokens
+	budget.mu.Unlock()
+	if tokens != 4 {
+		t.Fatalf("токенов %v, ожидалось не больше максимума 4", tokens)
+	}
+
+	var unlimited *RetryBudget
+	unlimited.onSuccess()
+	if !unlimited.onFailure() {
+		t.Fatal("nil-бюджет не должен ограничивать повторы")
+	}
+}
+
+func TestRetryBackoffRespectsContext(t *testing.T) {
+	client := NewMockClient(WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    3,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+		InitialBackoff: time.Hour,
+	}))
+	client.AddScript(retryMethod,
+		MockStep{Err: status.Error(codes.Unavailable, "недоступен")},
+		MockStep{Response: &healthpb.HealthCheckResponse{}},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := Invoke[healthpb.HealthCheckResponse](ctx, client, retryMethod, &healthpb.HealthCheckRequest{})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("вызов вернулся через %v, ожидался выход по отмене контекста", elapsed)
+	}
+	if Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("код %v, ожидался DeadlineExceeded", Code(err))
+	}
+	client.AssertNumberOfCalls(t, retryMethod, 1)
+}
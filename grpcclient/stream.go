@@ -0,0 +1,301 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/arrowwhi/go-utils/grpcclient/interceptors"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// StreamOption определяет функцию для настройки потока
+type StreamOption func(*streamOptions)
+
+type streamOptions struct {
+	timeout     time.Duration
+	metadata    metadata.MD
+	resume      ResumeFunc
+	callOptions []grpc.CallOption
+}
+
+// ResumeFunc формирует запрос для повторного открытия серверного потока после обрыва.
+// req — исходный запрос, last — последнее успешно полученное сообщение (nil, если сообщений не было).
+type ResumeFunc func(req interface{}, last interface{}) interface{}
+
+// WithStreamTimeout устанавливает дедлайн на все время жизни потока
+func WithStreamTimeout(timeout time.Duration) StreamOption {
+	return func(o *streamOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithStreamMetadata добавляет метаданные к потоку поверх метаданных клиента
+func WithStreamMetadata(md metadata.MD) StreamOption {
+	return func(o *streamOptions) {
+		o.metadata = metadata.Join(o.metadata, md)
+	}
+}
+
+// WithResume устанавливает обработчик, формирующий запрос при переподключении серверного потока
+func WithResume(resume ResumeFunc) StreamOption {
+	return func(o *streamOptions) {
+		o.resume = resume
+	}
+}
+
+// WithCallOptions передает grpc.CallOption при открытии потока
+func WithCallOptions(opts ...grpc.CallOption) StreamOption {
+	return func(o *streamOptions) {
+		o.callOptions = append(o.callOptions, opts...)
+	}
+}
+
+var _ grpc.ClientStream = (*Stream)(nil)
+
+// Stream представляет открытый gRPC поток
+type Stream struct {
+	client      *Client
+	desc        *grpc.StreamDesc
+	method      string
+	ctx         context.Context
+	cancel      context.CancelFunc
+	stream      grpc.ClientStream
+	callOptions []grpc.CallOption
+
+	// Поля для переподключения серверного потока
+	reconnect bool
+	req       interface{}
+	last      interface{}
+	resume    ResumeFunc
+	failures  int
+
+	start   time.Time
+	span    trace.Span
+	endOnce sync.Once
+}
+
+// OpenStream открывает поток произвольного типа (серверный, клиентский или двунаправленный)
+func (c *Client) OpenStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...StreamOption) (*Stream, error) {
+	s := c.newStream(ctx, desc, method, opts...)
+	if err := s.open(); err != nil {
+		s.finish(err)
+		return nil, err
+	}
+	return s, nil
+}
+
+// OpenServerStream открывает серверный поток: отправляет req и закрывает отправку.
+// При обрыве с кодом Unavailable поток переоткрывается по политике повторов метода,
+// запрос для переоткрытия формирует обработчик из WithResume.
+func (c *Client) OpenServerStream(ctx context.Context, method string, req interface{}, opts ...StreamOption) (*Stream, error) {
+	s := c.newStream(ctx, &grpc.StreamDesc{ServerStreams: true}, method, opts...)
+	s.reconnect = true
+	s.req = req
+	if err := s.openServerStream(req); err != nil {
+		s.finish(err)
+		return nil, err
+	}
+	return s, nil
+}
+
+func (c *Client) newStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...StreamOption) *Stream {
+	o := &streamOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if md := metadata.Join(c.metadata, o.metadata); len(md) > 0 {
+		existing, _ := metadata.FromOutgoingContext(ctx)
+		ctx = metadata.NewOutgoingContext(ctx, metadata.Join(md, existing))
+	}
+	// Переоткрытый серверный поток передает тот же x-request-id
+	if c.requestID {
+		ctx = interceptors.WithRequestID(ctx)
+	}
+
+	var cancel context.CancelFunc
+	if o.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	ctx, span := c.tracing.start(ctx, c.target, method)
+
+	return &Stream{
+		client:      c,
+		desc:        desc,
+		method:      method,
+		ctx:         ctx,
+		cancel:      cancel,
+		resume:      o.resume,
+		callOptions: o.callOptions,
+		start:       time.Now(),
+		span:        span,
+	}
+}
+
+func (s *Stream) open() error {
+	if s.client.conn == nil {
+		return wrapError(status.Errorf(codes.Unimplemented, "потоки не поддерживаются клиентом без соединения: %s", s.method))
+	}
+	ctx := s.ctx
+	rule, err := s.client.faults.inject(ctx, s.method)
+	if err != nil {
+		return wrapError(err)
+	}
+	stream, err := s.client.conn.NewStream(ctx, s.desc, s.method, s.callOptions...)
+	if err != nil {
+		return wrapError(err)
+	}
+	s.stream = abortStream(stream, s.method, rule)
+	return nil
+}
+
+func (s *Stream) openServerStream(req interface{}) error {
+	if err := s.open(); err != nil {
+		return err
+	}
+	if err := s.stream.SendMsg(req); err != nil && !errors.Is(err, io.EOF) {
+		return wrapError(err)
+	}
+	if err := s.stream.CloseSend(); err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
+
+// Context возвращает контекст потока
+func (s *Stream) Context() context.Context {
+	return s.ctx
+}
+
+// Header возвращает заголовочные метаданные, полученные от сервера
+func (s *Stream) Header() (metadata.MD, error) {
+	md, err := s.stream.Header()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return md, nil
+}
+
+// Trailer возвращает завершающие метаданные; доступны после окончания потока
+func (s *Stream) Trailer() metadata.MD {
+	return s.stream.Trailer()
+}
+
+// SendMsg отправляет сообщение в поток
+func (s *Stream) SendMsg(m interface{}) error {
+	err := s.stream.SendMsg(m)
+	if err == nil {
+		s.client.metrics.sent(s.method)
+	}
+	if err == nil || errors.Is(err, io.EOF) {
+		// io.EOF означает, что поток закрыт сервером; причину вернет RecvMsg
+		return err
+	}
+	return wrapError(err)
+}
+
+// RecvMsg получает сообщение из потока. По окончании потока возвращает io.EOF.
+// В потоке без серверной потоковой передачи (CloseAndRecv) поток завершается
+// вместе с получением единственного ответа.
+func (s *Stream) RecvMsg(m interface{}) error {
+	for {
+		err := s.stream.RecvMsg(m)
+		if err == nil {
+			s.client.metrics.received(s.method)
+			s.last = m
+			s.failures = 0
+			if !s.desc.ServerStreams {
+				// grpc уже получил статус вызова: EOF после ответа не придет
+				s.finish(nil)
+			}
+			return nil
+		}
+		if errors.Is(err, io.EOF) {
+			s.finish(nil)
+			return err
+		}
+		if !s.canReconnect(err) {
+			err = wrapError(err)
+			s.finish(err)
+			return err
+		}
+
+		s.failures++
+		delay := s.client.retryPolicyFor(s.method).backoff(s.failures - 1)
+		s.client.tracing.retry(s.ctx, s.failures-1, delay, err)
+		if err := sleepContext(s.ctx, delay); err != nil {
+			err = wrapError(err)
+			s.finish(err)
+			return err
+		}
+
+		req := s.req
+		if s.resume != nil {
+			req = s.resume(s.req, s.last)
+		}
+		if err := s.openServerStream(req); err != nil {
+			s.finish(err)
+			return err
+		}
+	}
+}
+
+// canReconnect проверяет, можно ли переоткрыть серверный поток после ошибки
+func (s *Stream) canReconnect(err error) bool {
+	return s.reconnect &&
+		status.Code(err) == codes.Unavailable &&
+		s.failures+1 < s.client.retryPolicyFor(s.method).MaxAttempts &&
+		s.ctx.Err() == nil
+}
+
+// CloseSend закрывает отправляющую сторону потока
+func (s *Stream) CloseSend() error {
+	if err := s.stream.CloseSend(); err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
+
+// Close отменяет поток и освобождает его ресурсы
+func (s *Stream) Close() {
+	s.finish(nil)
+}
+
+// finish завершает спан потока, учитывает его в метриках и отменяет поток
+func (s *Stream) finish(err error) {
+	s.endOnce.Do(func() {
+		endSpan(s.span, err)
+		s.client.metrics.observeStream(s.method, s.start, err)
+	})
+	s.cancel()
+}
+
+// Send отправляет типизированное сообщение в поток
+func Send[Req proto.Message](s *Stream, req Req) error {
+	return s.SendMsg(req)
+}
+
+// Recv получает типизированное сообщение из потока
+func Recv[Resp any, PResp interface {
+	*Resp
+	proto.Message
+}](s *Stream) (PResp, error) {
+	resp := PResp(new(Resp))
+	if err := s.RecvMsg(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
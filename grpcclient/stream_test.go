@@ -0,0 +1,103 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestServerStreamResumesAfterUnavailable(t *testing.T) {
+	client, metrics, exporter, server := newNumbersClient(t, WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var resumedAfter []int64
+	resume := func(req, last interface{}) interface{} {
+		if last == nil {
+			t.Error("ResumeFunc не получил последнее сообщение")
+			return req
+		}
+		value := last.(*wrapperspb.Int64Value).GetValue()
+		resumedAfter = append(resumedAfter, value)
+		return wrapperspb.Int64(value + 1)
+	}
+
+	stream, err := client.OpenServerStream(ctx, flakyMethod, wrapperspb.Int64(1), WithResume(resume))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int64
+	for {
+		msg, err := Recv[wrapperspb.Int64Value](stream)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, msg.GetValue())
+	}
+
+	if want := []int64{1, 2, 3, 4, 5}; !slices.Equal(got, want) {
+		t.Fatalf("получено %v, ожидалось %v", got, want)
+	}
+	if !slices.Equal(resumedAfter, []int64{2}) {
+		t.Fatalf("ResumeFunc вызван после %v, ожидалось [2]", resumedAfter)
+	}
+	if opens := server.flakyOpens.Load(); opens != 2 {
+		t.Fatalf("поток открыт %d раз, ожидалось 2", opens)
+	}
+
+	// Переоткрытие не завершает поток: спан и метрики учитываются один раз
+	stream.Close()
+	if n := len(exporter.GetSpans()); n != 1 {
+		t.Fatalf("завершено %d спанов, ожидался 1", n)
+	}
+	if got := testutil.ToFloat64(metrics.requests.WithLabelValues(flakyMethod, "OK")); got != 1 {
+		t.Fatalf("grpc_client_requests_total{code=OK} = %v, ожидалось 1", got)
+	}
+	if got := testutil.CollectAndCount(metrics.requests); got != 1 {
+		t.Fatalf("grpc_client_requests_total: %d серий, ожидалась 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.streamReceived.WithLabelValues(flakyMethod)); got != 5 {
+		t.Fatalf("grpc_client_stream_messages_received_total = %v, ожидалось 5", got)
+	}
+}
+
+func TestServerStreamFailsWhenReconnectsExhausted(t *testing.T) {
+	client, metrics, exporter, _ := newNumbersClient(t, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.OpenServerStream(ctx, flakyMethod, wrapperspb.Int64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		_, err = Recv[wrapperspb.Int64Value](stream)
+		if err != nil {
+			break
+		}
+	}
+	if code := Code(err); code != codes.Unavailable {
+		t.Fatalf("код %s, ожидался Unavailable", code)
+	}
+	if n := len(exporter.GetSpans()); n != 1 {
+		t.Fatalf("завершено %d спанов, ожидался 1", n)
+	}
+	if got := testutil.ToFloat64(metrics.requests.WithLabelValues(flakyMethod, "Unavailable")); got != 1 {
+		t.Fatalf("grpc_client_requests_total{code=Unavailable} = %v, ожидалось 1", got)
+	}
+}
@@ -39,4 +39,22 @@ func main() {
 	// Cast the response to the expected type
 	resp := response.(*testproto.Resp)
 	fmt.Printf("Ответ сервера: %v\n", resp)
+
+	// Тот же вызов через типизированный API, без приведения типа
+	typed, err := grpcclient.Invoke[testproto.Resp](context.Background(), client,
+		testproto.UsersService_GetStatusInfo_FullMethodName, &testproto.Req{Input: 3})
+	if err != nil {
+		log.Printf("Ошибка запроса: %v", err)
+		return
+	}
+	fmt.Printf("Ответ сервера: %v\n", typed)
+
+	// Сгенерированный клиент поверх Client использует его повторы, метаданные и таймауты
+	users := grpcclient.Bind(client, testproto.NewUsersServiceClient)
+	typed, err = users.GetStatusInfo(context.Background(), &testproto.Req{Input: 4})
+	if err != nil {
+		log.Printf("Ошибка запроса: %v", err)
+		return
+	}
+	fmt.Printf("Ответ сервера: %v\n", typed)
 }
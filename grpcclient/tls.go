@@ -0,0 +1,214 @@
+package grpcclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// tlsOptions хранит настройки TLS соединения клиента
+type tlsOptions struct {
+	caFile         string
+	certFile       string
+	keyFile        string
+	serverName     string
+	reloadInterval time.Duration
+}
+
+// WithTLS включает TLS с проверкой сертификата сервера.
+// Если caFile пустой, используются системные корневые сертификаты.
+func WithTLS(caFile string) ClientOption {
+	return func(c *Client) {
+		c.tlsOptions().caFile = caFile
+	}
+}
+
+// WithMTLS включает взаимный TLS: сервер проверяется по caFile,
+// клиент предъявляет сертификат из пары certFile/keyFile
+func WithMTLS(caFile, certFile, keyFile string) ClientOption {
+	return func(c *Client) {
+		t := c.tlsOptions()
+		t.caFile = caFile
+		t.certFile = certFile
+		t.keyFile = keyFile
+	}
+}
+
+// WithServerNameOverride переопределяет имя сервера, с которым сверяется сертификат
+func WithServerNameOverride(serverName string) ClientOption {
+	return func(c *Client) {
+		c.tlsOptions().serverName = serverName
+	}
+}
+
+// WithCertReload включает перечитывание сертификатов с диска.
+// Файлы проверяются не чаще одного раза за interval и перечитываются при изменении.
+func WithCertReload(interval time.Duration) ClientOption {
+	return func(c *Client) {
+		c.tlsOptions().reloadInterval = interval
+	}
+}
+
+// tlsOptions возвращает настройки TLS, создавая их при первом обращении
+func (c *Client) tlsOptions() *tlsOptions {
+	if c.tls == nil {
+		c.tls = &tlsOptions{}
+	}
+	return c.tls
+}
+
+// certReloader загружает CA и клиентский сертификат и следит за изменением файлов
+type certReloader struct {
+	opts tlsOptions
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	modTimes  map[string]time.Time
+	roots     *x509.CertPool
+	cert      *tls.Certificate
+}
+
+func newCertReloader(opts tlsOptions) (*certReloader, error) {
+	r := &certReloader{opts: opts, modTimes: make(map[string]time.Time)}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	r.checkedAt = time.Now()
+	return r, nil
+}
+
+// load читает все файлы сертификатов с диска
+func (r *certReloader) load() error {
+	var roots *x509.CertPool
+	if r.opts.caFile != "" {
+		pem, err := os.ReadFile(r.opts.caFile)
+		if err != nil {
+			return fmt.Errorf("ошибка чтения CA сертификата: %w", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("CA файл %s не содержит сертификатов", r.opts.caFile)
+		}
+	}
+
+	var cert *tls.Certificate
+	if r.opts.certFile != "" || r.opts.keyFile != "" {
+		pair, err := tls.LoadX509KeyPair(r.opts.certFile, r.opts.keyFile)
+		if err != nil {
+			return fmt.Errorf("ошибка загрузки клиентского сертификата: %w", err)
+		}
+		cert = &pair
+	}
+
+	for _, name := range r.files() {
+		if info, err := os.Stat(name); err == nil {
+			r.modTimes[name] = info.ModTime()
+		}
+	}
+	r.roots = roots
+	r.cert = cert
+	return nil
+}
+
+func (r *certReloader) files() []string {
+	var files []string
+	for _, name := range []string{r.opts.caFile, r.opts.certFile, r.opts.keyFile} {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+// changed проверяет, изменился ли какой-либо из файлов с момента последней загрузки
+func (r *certReloader) changed() bool {
+	for _, name := range r.files() {
+		info, err := os.Stat(name)
+		if err != nil {
+			continue
+		}
+		if !info.ModTime().Equal(r.modTimes[name]) {
+			return true
+		}
+	}
+	return false
+}
+
+// config возвращает актуальную TLS конфигурацию для имени сервера serverName,
+// при необходимости перечитывая файлы
+func (r *certReloader) config(serverName string) *tls.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.opts.reloadInterval > 0 && time.Since(r.checkedAt) >= r.opts.reloadInterval {
+		r.checkedAt = time.Now()
+		// При ошибке продолжаем работать с ранее загруженными сертификатами
+		if r.changed() {
+			_ = r.load()
+		}
+	}
+
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    r.roots,
+		ServerName: serverName,
+	}
+	if r.cert != nil {
+		cert := r.cert
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return cert, nil
+		}
+	}
+	return cfg
+}
+
+// reloadingCredentials реализует credentials.TransportCredentials,
+// собирая TLS конфигурацию заново для каждого рукопожатия.
+// Копии из Clone используют общий certReloader, но свое имя сервера.
+type reloadingCredentials struct {
+	reloader   *certReloader
+	serverName string
+}
+
+func (rc *reloadingCredentials) ClientHandshake(ctx context.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return credentials.NewTLS(rc.reloader.config(rc.serverName)).ClientHandshake(ctx, authority, rawConn)
+}
+
+func (rc *reloadingCredentials) ServerHandshake(net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, fmt.Errorf("серверное рукопожатие не поддерживается клиентскими credentials")
+}
+
+// Info не содержит версию TLS: она согласуется при рукопожатии
+// и доступна в credentials.TLSInfo соединения
+func (rc *reloadingCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{
+		SecurityProtocol: "tls",
+		ServerName:       rc.serverName,
+	}
+}
+
+func (rc *reloadingCredentials) Clone() credentials.TransportCredentials {
+	return &reloadingCredentials{reloader: rc.reloader, serverName: rc.serverName}
+}
+
+// OverrideServerName меняет имя сервера только у этой копии credentials
+func (rc *reloadingCredentials) OverrideServerName(serverName string) error {
+	rc.serverName = serverName
+	return nil
+}
+
+// newTransportCredentials создает credentials для TLS соединения
+func newTransportCredentials(opts tlsOptions) (credentials.TransportCredentials, error) {
+	reloader, err := newCertReloader(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &reloadingCredentials{reloader: reloader, serverName: opts.serverName}, nil
+}
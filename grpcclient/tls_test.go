@@ -0,0 +1,313 @@
+package grpcclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+)
+
+// testCA — локальный удостоверяющий центр для тестов TLS
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue выпускает сертификат с именем commonName для localhost
+func (ca *testCA) issue(t *testing.T, commonName string) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// serverTLS возвращает TLS конфигурацию сервера; clientCA != nil требует сертификат клиента
+func (ca *testCA) serverTLS(t *testing.T, clientCA *testCA) *tls.Config {
+	t.Helper()
+	certPEM, keyPEM := ca.issue(t, "server")
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{pair}}
+	if clientCA != nil {
+		cfg.ClientCAs = x509.NewCertPool()
+		cfg.ClientCAs.AddCert(clientCA.cert)
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// startTLSServer запускает сервер grpc.health.v1 с TLS и возвращает его адрес
+// и канал с CommonName клиентских сертификатов
+func startTLSServer(t *testing.T, cfg *tls.Config) (string, <-chan string) {
+	t.Helper()
+	peers := make(chan string, 16)
+	srv := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(cfg)),
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if p, ok := peer.FromContext(ctx); ok {
+				if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+					peers <- tlsInfo.State.PeerCertificates[0].Subject.CommonName
+				}
+			}
+			return handler(ctx, req)
+		}),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String(), peers
+}
+
+func TestTLS(t *testing.T) {
+	ca := newTestCA(t)
+	addr, _ := startTLSServer(t, ca.serverTLS(t, nil))
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writeFile(t, caFile, ca.pem)
+
+	client, err := NewClient(addr, WithTLS(caFile), WithServerNameOverride("localhost"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.CheckHealth(ctx, ""); err != nil {
+		t.Fatalf("вызов по TLS: %v", err)
+	}
+}
+
+func TestTLSUnknownAuthority(t *testing.T) {
+	addr, _ := startTLSServer(t, newTestCA(t).serverTLS(t, nil))
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writeFile(t, caFile, newTestCA(t).pem)
+
+	client, err := NewClient(addr, WithTLS(caFile), WithEagerConnect(time.Second))
+	if err == nil {
+		client.Close()
+		t.Fatal("ожидалась ошибка соединения с сервером, подписанным другим CA")
+	}
+}
+
+func TestMTLS(t *testing.T) {
+	ca := newTestCA(t)
+	addr, peers := startTLSServer(t, ca.serverTLS(t, ca))
+
+	dir := t.TempDir()
+	caFile, certFile, keyFile := filepath.Join(dir, "ca.pem"), filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	certPEM, keyPEM := ca.issue(t, "client")
+	writeFile(t, caFile, ca.pem)
+	writeFile(t, certFile, certPEM)
+	writeFile(t, keyFile, keyPEM)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := NewClient(addr, WithMTLS(caFile, certFile, keyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if err := client.CheckHealth(ctx, ""); err != nil {
+		t.Fatalf("вызов по mTLS: %v", err)
+	}
+	if cn := <-peers; cn != "client" {
+		t.Fatalf("сервер получил сертификат %q, ожидался client", cn)
+	}
+
+	// Без клиентского сертификата сервер отклоняет рукопожатие
+	anonymous, err := NewClient(addr, WithTLS(caFile), WithTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer anonymous.Close()
+	shortCtx, shortCancel := context.WithTimeout(ctx, time.Second)
+	defer shortCancel()
+	if err := anonymous.CheckHealth(shortCtx, ""); err == nil {
+		t.Fatal("ожидалась ошибка вызова без клиентского сертификата")
+	}
+}
+
+func TestCertReload(t *testing.T) {
+	ca := newTestCA(t)
+	serverCfg := ca.serverTLS(t, ca)
+
+	dir := t.TempDir()
+	caFile, certFile, keyFile := filepath.Join(dir, "ca.pem"), filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	writeFile(t, caFile, ca.pem)
+	certPEM, keyPEM := ca.issue(t, "first")
+	writeFile(t, certFile, certPEM)
+	writeFile(t, keyFile, keyPEM)
+
+	creds, err := newTransportCredentials(tlsOptions{
+		caFile:         caFile,
+		certFile:       certFile,
+		keyFile:        keyFile,
+		serverName:     "localhost",
+		reloadInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cn := handshakeClientCN(t, creds, serverCfg); cn != "first" {
+		t.Fatalf("сервер получил сертификат %q, ожидался first", cn)
+	}
+
+	certPEM, keyPEM = ca.issue(t, "second")
+	writeFile(t, certFile, certPEM)
+	writeFile(t, keyFile, keyPEM)
+	// Время изменения файлов должно отличаться от загруженного
+	future := time.Now().Add(time.Minute)
+	for _, name := range []string{certFile, keyFile} {
+		if err := os.Chtimes(name, future, future); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if cn := handshakeClientCN(t, creds, serverCfg); cn != "second" {
+		t.Fatalf("после перечитывания сервер получил сертификат %q, ожидался second", cn)
+	}
+}
+
+// handshakeClientCN выполняет рукопожатие creds с TLS сервером и возвращает
+// CommonName сертификата, предъявленного клиентом
+func handshakeClientCN(t *testing.T, creds credentials.TransportCredentials, serverCfg *tls.Config) string {
+	t.Helper()
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	cn := make(chan string, 1)
+	go func() {
+		cfg := serverCfg.Clone()
+		cfg.NextProtos = []string{"h2"}
+		// Без билетов сессии сервер ничего не пишет после рукопожатия: net.Pipe не буферизует запись
+		cfg.SessionTicketsDisabled = true
+		conn := tls.Server(serverConn, cfg)
+		if err := conn.Handshake(); err != nil {
+			cn <- ""
+			return
+		}
+		cn <- conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// Соединение закрывается через clientConn: Close у tls.Conn ждет отправки close_notify
+	_, _, err := creds.ClientHandshake(ctx, "localhost", clientConn)
+	if err != nil {
+		t.Fatalf("рукопожатие: %v", err)
+	}
+	return <-cn
+}
+
+func TestOverrideServerNameDoesNotAffectOriginal(t *testing.T) {
+	ca := newTestCA(t)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writeFile(t, caFile, ca.pem)
+
+	creds, err := newTransportCredentials(tlsOptions{caFile: caFile, serverName: "original"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := creds.Clone()
+	if err := clone.OverrideServerName("override"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := creds.Info().ServerName; got != "original" {
+		t.Fatalf("имя сервера исходных credentials изменилось: %q", got)
+	}
+	if got := clone.Info().ServerName; got != "override" {
+		t.Fatalf("имя сервера копии: %q, ожидалось override", got)
+	}
+	if got := creds.Info().SecurityVersion; got != "" {
+		t.Fatalf("версия TLS не должна задаваться до рукопожатия: %q", got)
+	}
+}
@@ -0,0 +1,122 @@
+package grpcclient
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tracerName — имя инструментирующей библиотеки в спанах
+const tracerName = "github.com/arrowwhi/go-utils/grpcclient"
+
+// TracingConfig описывает параметры трассировки вызовов
+type TracingConfig struct {
+	// TracerProvider — источник трассировщика; по умолчанию глобальный otel.GetTracerProvider()
+	TracerProvider trace.TracerProvider
+	// Propagator — формат передачи контекста в метаданных; по умолчанию W3C traceparent
+	Propagator propagation.TextMapPropagator
+}
+
+// tracing создает спаны вызовов и передает контекст трассировки серверу
+type tracing struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// WithTracing включает OpenTelemetry спаны для унарных вызовов и потоков клиента.
+// Контекст трассировки передается серверу в исходящих метаданных.
+func WithTracing(cfg TracingConfig) ClientOption {
+	return func(c *Client) {
+		if cfg.TracerProvider == nil {
+			cfg.TracerProvider = otel.GetTracerProvider()
+		}
+		if cfg.Propagator == nil {
+			cfg.Propagator = propagation.TraceContext{}
+		}
+		c.tracing = &tracing{
+			tracer:     cfg.TracerProvider.Tracer(tracerName),
+			propagator: cfg.Propagator,
+		}
+	}
+}
+
+// start открывает спан вызова и добавляет контекст трассировки в исходящие метаданные
+func (t *tracing) start(ctx context.Context, target, method string) (context.Context, trace.Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	name := strings.TrimPrefix(method, "/")
+	service, rpcMethod, _ := strings.Cut(name, "/")
+	ctx, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", service),
+			attribute.String("rpc.method", rpcMethod),
+			attribute.String("server.address", target),
+		),
+	)
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	t.propagator.Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+// retry отмечает в спане вызова повторную попытку
+func (t *tracing) retry(ctx context.Context, attempt int, delay time.Duration, err error) {
+	if t == nil {
+		return
+	}
+	trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+		attribute.Int("rpc.grpc.attempt", attempt+1),
+		attribute.String("rpc.grpc.status_code", status.Code(err).String()),
+		attribute.Int64("rpc.grpc.retry_delay_ms", delay.Milliseconds()),
+	))
+}
+
+// endSpan завершает спан с итоговым кодом вызова
+func endSpan(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	if code != codes.OK {
+		span.SetStatus(otelcodes.Error, status.Convert(err).Message())
+	}
+	span.End()
+}
+
+// metadataCarrier позволяет пропагатору записывать и читать метаданные gRPC
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
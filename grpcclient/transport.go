@@ -0,0 +1,67 @@
+package grpcclient
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip" // регистрирует компрессор gzip
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+)
+
+// WithKeepalive включает keepalive пинги соединения.
+// Сервер должен разрешать пинги с таким интервалом, иначе он закроет соединение:
+// для grpcserver interval не должен быть меньше KEEPALIVE_MIN_TIME (по умолчанию 10s),
+// а permitWithoutStream требует KEEPALIVE_PERMIT_WITHOUT_STREAM=true.
+func WithKeepalive(interval, timeout time.Duration, permitWithoutStream bool) ClientOption {
+	return func(c *Client) {
+		c.dialOptions = append(c.dialOptions, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                interval,
+			Timeout:             timeout,
+			PermitWithoutStream: permitWithoutStream,
+		}))
+	}
+}
+
+// WithCompression включает сжатие исходящих сообщений зарегистрированным компрессором, например "gzip"
+func WithCompression(name string) ClientOption {
+	return func(c *Client) {
+		c.dialOptions = append(c.dialOptions, grpc.WithDefaultCallOptions(grpc.UseCompressor(name)))
+	}
+}
+
+// WithMaxMessageSize ограничивает размер получаемых и отправляемых сообщений в байтах.
+// Нулевое значение оставляет ограничение gRPC по умолчанию (4 МБ на получение).
+// При WithCompression ограничение на отправку применяется к сжатому сообщению.
+// Для больших сообщений ограничение сервера (MAX_RECV_MSG_SIZE в grpc_config) тоже нужно увеличить.
+func WithMaxMessageSize(maxRecv, maxSend int) ClientOption {
+	return func(c *Client) {
+		var opts []grpc.CallOption
+		if maxRecv > 0 {
+			opts = append(opts, grpc.MaxCallRecvMsgSize(maxRecv))
+		}
+		if maxSend > 0 {
+			opts = append(opts, grpc.MaxCallSendMsgSize(maxSend))
+		}
+		if len(opts) > 0 {
+			c.dialOptions = append(c.dialOptions, grpc.WithDefaultCallOptions(opts...))
+		}
+	}
+}
+
+// WithDefaultMetadata добавляет метаданные ко всем вызовам клиента
+func WithDefaultMetadata(md metadata.MD) ClientOption {
+	return func(c *Client) {
+		c.metadata = metadata.Join(c.metadata, md)
+	}
+}
+
+// WithLoadBalancingPolicy устанавливает политику балансировки, например "round_robin"
+func WithLoadBalancingPolicy(policy string) ClientOption {
+	return func(c *Client) {
+		c.dialOptions = append(c.dialOptions, grpc.WithDefaultServiceConfig(
+			fmt.Sprintf(`{"loadBalancingConfig": [{%q: {}}]}`, policy),
+		))
+	}
+}
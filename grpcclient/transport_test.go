@@ -0,0 +1,62 @@
+package grpcclient
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestMaxMessageSize(t *testing.T) {
+	server := startCountingServer(t)
+	client, err := NewClient(server.addr, WithMaxMessageSize(0, 512))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Запрос в пределах ограничения проходит
+	if _, err := Invoke[healthpb.HealthCheckResponse](ctx, client, healthpb.Health_Check_FullMethodName, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Запрос больше ограничения отклоняется до отправки на сервер
+	server.hits.Store(0)
+	big := &healthpb.HealthCheckRequest{Service: strings.Repeat("x", 1024)}
+	_, err = Invoke[healthpb.HealthCheckResponse](ctx, client, healthpb.Health_Check_FullMethodName, big)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("запрос больше maxSend: %v, ожидался ResourceExhausted", err)
+	}
+	if hits := server.hits.Load(); hits != 0 {
+		t.Fatalf("сервер получил %d вызовов, ожидалось 0", hits)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	server := startCountingServer(t)
+	// Ограничение применяется к сжатому сообщению: повторяющиеся данные укладываются в него
+	client, err := NewClient(server.addr, WithCompression("gzip"), WithMaxMessageSize(0, 512))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &healthpb.HealthCheckRequest{Service: strings.Repeat("x", 1024)}
+	_, err = Invoke[healthpb.HealthCheckResponse](ctx, client, healthpb.Health_Check_FullMethodName, req)
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("сжатый запрос: %v, ожидался NotFound от сервера", err)
+	}
+	if hits := server.hits.Load(); hits != 1 {
+		t.Fatalf("сервер получил %d вызовов, ожидался 1", hits)
+	}
+}
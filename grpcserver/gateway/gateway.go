@@ -2,16 +2,21 @@ package gateway
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"github.com/arrowwhi/go-utils/grpcserver/grpc_config"
+	"github.com/arrowwhi/go-utils/grpcserver/interceptors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
 // Option defines a function type for configuring the Gateway.
@@ -23,6 +28,14 @@ type Gateway struct {
 	logger       *zap.Logger
 	handlers     []func(ctx context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error
 	dialOptions  []grpc.DialOption
+	tlsConfig    *tls.Config
+	httpHandlers []httpHandler
+}
+
+// httpHandler is a plain HTTP handler served next to the gRPC gateway routes.
+type httpHandler struct {
+	pattern string
+	handler http.Handler
 }
 
 // NewGateway creates a new Gateway instance with the provided options.
@@ -53,9 +66,26 @@ func WithDialOptions(dialOpts ...grpc.DialOption) Option {
 	}
 }
 
+// WithTLSConfig makes the Gateway serve HTTPS with the given TLS configuration.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(g *Gateway) {
+		g.tlsConfig = tlsConfig
+	}
+}
+
+// WithHTTPHandler serves a plain HTTP handler on the given pattern,
+// e.g. health endpoints. It takes precedence over the gRPC gateway routes.
+func WithHTTPHandler(pattern string, handler http.Handler) Option {
+	return func(g *Gateway) {
+		g.httpHandlers = append(g.httpHandlers, httpHandler{pattern: pattern, handler: handler})
+	}
+}
+
 // Start launches the HTTP gateway server.
 func (g *Gateway) Start(ctx context.Context) error {
-	conn, err := grpc.NewClient(fmt.Sprintf("localhost:%s", g.ServerConfig.GRPCPort), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// Custom dial options are applied last so they can override the default credentials.
+	dialOptions := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, g.dialOptions...)
+	conn, err := grpc.NewClient(fmt.Sprintf("localhost:%s", g.ServerConfig.GRPCPort), dialOptions...)
 	if err != nil {
 		return err
 	}
@@ -66,9 +96,7 @@ func (g *Gateway) Start(ctx context.Context) error {
 		}
 	}(conn)
 
-	gwmux := runtime.NewServeMux(
-	//opts...,
-	)
+	gwmux := newServeMux()
 
 	for _, impl := range g.handlers {
 		if err := impl(ctx, gwmux, conn); err != nil {
@@ -80,6 +108,10 @@ func (g *Gateway) Start(ctx context.Context) error {
 
 	//g.registerSwaggerHandler(mux)
 
+	for _, h := range g.httpHandlers {
+		mux.Handle(h.pattern, h.handler)
+	}
+
 	mux.Handle("/", gwmux)
 
 	var httpHandler http.Handler
@@ -99,7 +131,13 @@ func (g *Gateway) Start(ctx context.Context) error {
 
 	g.logger.Info(fmt.Sprintf("gRPC GW starting on address - %s", g.ServerConfig.GatewayPort))
 
-	if err := httpServer.ListenAndServe(); err != nil {
+	if g.tlsConfig != nil {
+		httpServer.TLSConfig = g.tlsConfig.Clone()
+		err = httpServer.ListenAndServeTLS("", "")
+	} else {
+		err = httpServer.ListenAndServe()
+	}
+	if err != nil {
 		if !errors.Is(err, http.ErrServerClosed) {
 			g.logger.Error(fmt.Sprintf("failed to start http gateway server: %v", err))
 			return err
@@ -110,3 +148,32 @@ func (g *Gateway) Start(ctx context.Context) error {
 
 	return nil
 }
+
+// newServeMux creates the gateway mux that forwards the verified client certificate
+// and drops client-supplied forwarded certificates.
+func newServeMux() *runtime.ServeMux {
+	return runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		runtime.WithMetadata(forwardClientCert),
+	)
+}
+
+// incomingHeaderMatcher drops client-supplied forwarded certificates so that
+// only the certificate verified by the gateway reaches the gRPC server.
+func incomingHeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, runtime.MetadataHeaderPrefix+interceptors.ForwardedClientCertKey) {
+		return "", false
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// forwardClientCert passes the verified HTTP client certificate to the gRPC server.
+func forwardClientCert(_ context.Context, r *http.Request) metadata.MD {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return metadata.Pairs(
+		interceptors.ForwardedClientCertKey,
+		base64.StdEncoding.EncodeToString(r.TLS.PeerCertificates[0].Raw),
+	)
+}
@@ -0,0 +1,119 @@
+package gateway
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arrowwhi/go-utils/grpcserver/interceptors"
+	pb "github.com/arrowwhi/go-utils/grpcserver/test/proto"
+	"google.golang.org/grpc/metadata"
+)
+
+// selfSigned returns a self-signed certificate with the given common name.
+func selfSigned(t *testing.T, commonName string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// metadataService records the incoming gRPC metadata produced by the gateway.
+type metadataService struct {
+	pb.UnimplementedUsersServiceServer
+	md chan metadata.MD
+}
+
+func (s *metadataService) GetStatusInfo(ctx context.Context, req *pb.Req) (*pb.Resp, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.md <- md
+	return &pb.Resp{Input: req.GetInput()}, nil
+}
+
+// forwardedCerts sends a gateway request and returns the forwarded certificates the service saw.
+func forwardedCerts(t *testing.T, clientCert *tls.Certificate, header http.Header) []string {
+	t.Helper()
+	svc := &metadataService{md: make(chan metadata.MD, 1)}
+	mux := newServeMux()
+	if err := pb.RegisterUsersServiceHandlerServer(context.Background(), mux, svc); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(mux)
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	client := server.Client()
+	transport := client.Transport.(*http.Transport)
+	if clientCert != nil {
+		transport.TLSClientConfig.Certificates = []tls.Certificate{*clientCert}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/health", strings.NewReader(`{"input": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("gateway status %d", resp.StatusCode)
+	}
+	return (<-svc.md).Get(interceptors.ForwardedClientCertKey)
+}
+
+func TestGatewayForwardsVerifiedClientCert(t *testing.T) {
+	cert := selfSigned(t, "http-client")
+	spoofed := base64.StdEncoding.EncodeToString(selfSigned(t, "admin").Certificate[0])
+
+	// Client-supplied forwarded certificates are dropped; only the TLS peer certificate is passed on
+	got := forwardedCerts(t, &cert, http.Header{
+		"Grpc-Metadata-" + interceptors.ForwardedClientCertKey: {spoofed},
+		interceptors.ForwardedClientCertKey:                    {spoofed},
+	})
+	want := base64.StdEncoding.EncodeToString(cert.Certificate[0])
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("forwarded certificates %v, want only the TLS client certificate", got)
+	}
+}
+
+func TestGatewayDropsSpoofedCertWithoutClientCert(t *testing.T) {
+	spoofed := base64.StdEncoding.EncodeToString(selfSigned(t, "admin").Certificate[0])
+	got := forwardedCerts(t, nil, http.Header{
+		"Grpc-Metadata-" + interceptors.ForwardedClientCertKey: {spoofed},
+	})
+	if len(got) != 0 {
+		t.Fatalf("spoofed certificate forwarded: %v", got)
+	}
+}
@@ -1,5 +1,7 @@
 package grpc_config
 
+import "time"
+
 type Config struct {
 	ServiceName string `envconfig:"SERVICE_NAME" required:"true"`
 	Version     string `envconfig:"VERSION" required:"true"`
@@ -7,4 +9,39 @@ type Config struct {
 	GRPCPort       string `envconfig:"GRPC_PORT" default:"50051"`
 	GatewayPort    string `envconfig:"GW_PORT" default:"8080"`
 	PrometheusPort string `envconfig:"PROMETHEUS_PORT" default:"9090"`
+
+	TLSCertFile          string `envconfig:"TLS_CERT_FILE"`
+	TLSKeyFile           string `envconfig:"TLS_KEY_FILE"`
+	TLSClientCAFile      string `envconfig:"TLS_CLIENT_CA_FILE"`
+	TLSRequireClientCert bool   `envconfig:"TLS_REQUIRE_CLIENT_CERT" default:"false"`
+
+	// Keepalive: сервер пингует соединение после KEEPALIVE_TIME без активности.
+	// KEEPALIVE_MIN_TIME и KEEPALIVE_PERMIT_WITHOUT_STREAM определяют, какие пинги клиентов допустимы;
+	// значения по умолчанию разрешают любые настройки keepalive клиента grpc-go (минимум 10s).
+	KeepaliveTime                time.Duration `envconfig:"KEEPALIVE_TIME" default:"2h"`
+	KeepaliveTimeout             time.Duration `envconfig:"KEEPALIVE_TIMEOUT" default:"20s"`
+	KeepaliveMinTime             time.Duration `envconfig:"KEEPALIVE_MIN_TIME" default:"10s"`
+	KeepalivePermitWithoutStream bool          `envconfig:"KEEPALIVE_PERMIT_WITHOUT_STREAM" default:"true"`
+	MaxConnectionIdle            time.Duration `envconfig:"MAX_CONNECTION_IDLE" default:"0s"`
+	MaxConnectionAge             time.Duration `envconfig:"MAX_CONNECTION_AGE" default:"0s"`
+	MaxConnectionAgeGrace        time.Duration `envconfig:"MAX_CONNECTION_AGE_GRACE" default:"0s"`
+
+	MaxRecvMsgSize int `envconfig:"MAX_RECV_MSG_SIZE" default:"4194304"`
+	MaxSendMsgSize int `envconfig:"MAX_SEND_MSG_SIZE" default:"2147483647"`
+
+	// Компрессор ответов (например gzip) для клиентов, которые его поддерживают.
+	// Пусто — ответ сжимается тем же компрессором, что и запрос.
+	Compression string `envconfig:"COMPRESSION"`
+
+	// Проверки состояния сервиса для grpc.health.v1 и HTTP /healthz, /readyz
+	HealthCheckInterval time.Duration `envconfig:"HEALTH_CHECK_INTERVAL" default:"10s"`
+	HealthCheckTimeout  time.Duration `envconfig:"HEALTH_CHECK_TIMEOUT" default:"5s"`
+	// Порт для /healthz, /readyz и /livez по HTTP без TLS. Gateway тоже отвечает на эти пути,
+	// но при TLS_REQUIRE_CLIENT_CERT требует клиентский сертификат, которого нет у проб kubelet.
+	HealthPort string `envconfig:"HEALTH_PORT"`
+}
+
+// TLSEnabled reports whether the gRPC listener and the gateway should serve TLS.
+func (c Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
 }
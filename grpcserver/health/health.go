@@ -0,0 +1,319 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Checker проверяет доступность зависимости сервиса
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc позволяет использовать функцию как Checker
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Pinger — зависимость с методом Ping, например *postgres.Database
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping возвращает проверку, вызывающую p.Ping
+func Ping(p Pinger) Checker {
+	return CheckerFunc(p.Ping)
+}
+
+// GRPCHealthClient — клиент gRPC зависимости, например *grpcclient.Client
+type GRPCHealthClient interface {
+	// WaitReady ждет готовности соединения
+	WaitReady(ctx context.Context) error
+	// CheckHealth запрашивает статус сервиса через grpc.health.v1
+	CheckHealth(ctx context.Context, service string) error
+}
+
+// GRPCClient возвращает проверку готовности соединения клиента.
+// Если service не пустой, дополнительно запрашивается его статус через grpc.health.v1.
+func GRPCClient(c GRPCHealthClient, service string) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		if err := c.WaitReady(ctx); err != nil {
+			return err
+		}
+		if service == "" {
+			return nil
+		}
+		return c.CheckHealth(ctx, service)
+	})
+}
+
+// Config задает периодичность проверок
+type Config struct {
+	// Interval — период запуска проверок
+	Interval time.Duration
+	// Timeout — время на одну проверку
+	Timeout time.Duration
+}
+
+// Result — результат последнего запуска проверки
+type Result struct {
+	Name      string    `json:"name"`
+	Services  []string  `json:"services,omitempty"`
+	Healthy   bool      `json:"healthy"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+type check struct {
+	name     string
+	checker  Checker
+	services []string
+}
+
+// Health — сервис grpc.health.v1, статусы которого определяются проверками.
+// Проверка без списка сервисов влияет на все сервисы; общий статус (пустое имя сервиса)
+// равен SERVING, только если проходят все проверки.
+type Health struct {
+	cfg    Config
+	server *grpchealth.Server
+
+	mu       sync.RWMutex
+	checks   []check
+	services []string
+	results  map[string]Result
+	checked  bool
+	shutdown bool
+}
+
+// New создает Health без проверок
+func New(cfg Config) *Health {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	return &Health{
+		cfg:     cfg,
+		server:  grpchealth.NewServer(),
+		results: make(map[string]Result),
+	}
+}
+
+// AddCheck добавляет проверку. services — сервисы, статус которых зависит от проверки;
+// если не указаны, проверка влияет на все сервисы.
+func (h *Health) AddCheck(name string, checker Checker, services ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, check{name: name, checker: checker, services: services})
+}
+
+// Register регистрирует grpc.health.v1 на сервере и запоминает его сервисы.
+// До первого запуска проверок сервисы с проверками находятся в состоянии NOT_SERVING.
+func (h *Health) Register(s *grpc.Server) {
+	healthpb.RegisterHealthServer(s, h.server)
+
+	h.mu.Lock()
+	for name := range s.GetServiceInfo() {
+		if name == healthpb.Health_ServiceDesc.ServiceName || strings.HasPrefix(name, "grpc.reflection.") {
+			continue
+		}
+		h.services = append(h.services, name)
+	}
+	slices.Sort(h.services)
+	h.mu.Unlock()
+
+	h.updateStatuses()
+}
+
+// Run запускает проверки сразу и затем с периодом Interval до отмены контекста
+func (h *Health) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		h.CheckNow(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckNow выполняет все проверки параллельно и обновляет статусы сервисов
+func (h *Health) CheckNow(ctx context.Context) {
+	h.mu.RLock()
+	checks := slices.Clone(h.checks)
+	h.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	h.mu.Lock()
+	for _, r := range results {
+		h.results[r.Name] = r
+	}
+	h.checked = true
+	h.mu.Unlock()
+
+	h.updateStatuses()
+}
+
+func (h *Health) run(ctx context.Context, c check) Result {
+	ctx, cancel := context.WithTimeout(ctx, h.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.checker.Check(ctx)
+	r := Result{
+		Name:      c.name,
+		Services:  c.services,
+		Healthy:   err == nil,
+		Duration:  time.Since(start).Round(time.Millisecond).String(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// Shutdown переводит все сервисы в NOT_SERVING перед остановкой сервера.
+// Последующие проверки статусы не меняют.
+func (h *Health) Shutdown() {
+	h.mu.Lock()
+	h.shutdown = true
+	h.mu.Unlock()
+	h.server.Shutdown()
+}
+
+// Status возвращает текущий статус сервиса; пустое имя — общий статус
+func (h *Health) Status(service string) healthpb.HealthCheckResponse_ServingStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.status(service)
+}
+
+// Results возвращает результаты последнего запуска проверок
+func (h *Health) Results() []Result {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	results := make([]Result, 0, len(h.checks))
+	for _, c := range h.checks {
+		if r, ok := h.results[c.name]; ok {
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// status вычисляет статус сервиса по результатам проверок; вызывается под блокировкой
+func (h *Health) status(service string) healthpb.HealthCheckResponse_ServingStatus {
+	if h.shutdown {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	for _, c := range h.checks {
+		if service != "" && len(c.services) > 0 && !slices.Contains(c.services, service) {
+			continue
+		}
+		if r, ok := h.results[c.name]; !ok || !r.Healthy {
+			return healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
+
+// updateStatuses передает статусы сервисов в grpc.health.v1; Watch получает изменения
+func (h *Health) updateStatuses() {
+	h.mu.RLock()
+	statuses := map[string]healthpb.HealthCheckResponse_ServingStatus{"": h.status("")}
+	for _, service := range h.services {
+		statuses[service] = h.status(service)
+	}
+	h.mu.RUnlock()
+
+	for service, status := range statuses {
+		h.server.SetServingStatus(service, status)
+	}
+}
+
+// Handler возвращает HTTP обработчик с путями /healthz, /readyz и /livez
+func (h *Health) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", h.Healthz())
+	mux.Handle("/readyz", h.Readyz())
+	mux.Handle("/livez", h.Livez())
+	return mux
+}
+
+// Livez — HTTP проверка живости: 200, пока сервер не останавливается
+func (h *Health) Livez() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		h.mu.RLock()
+		shutdown := h.shutdown
+		h.mu.RUnlock()
+
+		if shutdown {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok\n"))
+	})
+}
+
+// Readyz — HTTP проверка готовности: 200, если общий статус SERVING, иначе 503
+func (h *Health) Readyz() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if status := h.Status(""); status != healthpb.HealthCheckResponse_SERVING {
+			http.Error(w, status.String(), http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok\n"))
+	})
+}
+
+// Healthz — HTTP отчет о состоянии: статусы сервисов и результаты проверок в JSON.
+// Код ответа как у Readyz.
+func (h *Health) Healthz() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		h.mu.RLock()
+		services := make(map[string]string, len(h.services))
+		for _, service := range h.services {
+			services[service] = h.status(service).String()
+		}
+		status := h.status("")
+		h.mu.RUnlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if status != healthpb.HealthCheckResponse_SERVING {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":   status.String(),
+			"services": services,
+			"checks":   h.Results(),
+		})
+	})
+}
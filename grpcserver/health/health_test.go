@@ -0,0 +1,233 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arrowwhi/go-utils/grpcclient"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	usersService  = "users.v1.UserService"
+	ordersService = "orders.v1.OrderService"
+)
+
+// toggle — проверка, результат которой меняется в тесте
+type toggle struct{ healthy atomic.Bool }
+
+func (c *toggle) Check(context.Context) error {
+	if c.healthy.Load() {
+		return nil
+	}
+	return errors.New("недоступна")
+}
+
+// newHealth создает Health с проверками db (влияет на все сервисы) и orders (только на ordersService)
+// и регистрирует его на gRPC сервере с сервисами usersService и ordersService
+func newHealth(t *testing.T) (*Health, *grpc.Server, *toggle, *toggle) {
+	t.Helper()
+	db, orders := &toggle{}, &toggle{}
+	h := New(Config{Timeout: time.Second})
+	h.AddCheck("db", db)
+	h.AddCheck("orders", orders, ordersService)
+
+	srv := grpc.NewServer()
+	for _, name := range []string{usersService, ordersService} {
+		srv.RegisterService(&grpc.ServiceDesc{ServiceName: name, HandlerType: (*interface{})(nil)}, struct{}{})
+	}
+	h.Register(srv)
+	return h, srv, db, orders
+}
+
+func expectStatus(t *testing.T, h *Health, want map[string]healthpb.HealthCheckResponse_ServingStatus) {
+	t.Helper()
+	for service, status := range want {
+		if got := h.Status(service); got != status {
+			t.Fatalf("статус %q: %v, ожидался %v", service, got, status)
+		}
+	}
+}
+
+func TestStatusAggregation(t *testing.T) {
+	h, _, db, orders := newHealth(t)
+	const (
+		serving    = healthpb.HealthCheckResponse_SERVING
+		notServing = healthpb.HealthCheckResponse_NOT_SERVING
+	)
+
+	// До первого запуска проверок сервисы не готовы
+	expectStatus(t, h, map[string]healthpb.HealthCheckResponse_ServingStatus{"": notServing, usersService: notServing, ordersService: notServing})
+
+	db.healthy.Store(true)
+	h.CheckNow(context.Background())
+	expectStatus(t, h, map[string]healthpb.HealthCheckResponse_ServingStatus{"": notServing, usersService: serving, ordersService: notServing})
+
+	orders.healthy.Store(true)
+	h.CheckNow(context.Background())
+	expectStatus(t, h, map[string]healthpb.HealthCheckResponse_ServingStatus{"": serving, usersService: serving, ordersService: serving})
+
+	db.healthy.Store(false)
+	h.CheckNow(context.Background())
+	expectStatus(t, h, map[string]healthpb.HealthCheckResponse_ServingStatus{"": notServing, usersService: notServing, ordersService: notServing})
+
+	db.healthy.Store(true)
+	h.CheckNow(context.Background())
+	h.Shutdown()
+	h.CheckNow(context.Background())
+	expectStatus(t, h, map[string]healthpb.HealthCheckResponse_ServingStatus{"": notServing, usersService: notServing, ordersService: notServing})
+}
+
+func TestCheckTimeout(t *testing.T) {
+	h := New(Config{Timeout: 50 * time.Millisecond})
+	h.AddCheck("slow", CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	start := time.Now()
+	h.CheckNow(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("проверка заняла %v, ожидалось ограничение Timeout", elapsed)
+	}
+	results := h.Results()
+	if len(results) != 1 || results[0].Healthy || results[0].Error == "" {
+		t.Fatalf("результаты %+v, ожидалась ошибка проверки", results)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	h, srv, db, _ := newHealth(t)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := healthpb.NewHealthClient(conn).Watch(ctx, &healthpb.HealthCheckRequest{Service: usersService})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := func(want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.GetStatus() != want {
+			t.Fatalf("Watch: %v, ожидался %v", resp.GetStatus(), want)
+		}
+	}
+
+	expect(healthpb.HealthCheckResponse_NOT_SERVING)
+	db.healthy.Store(true)
+	h.CheckNow(ctx)
+	expect(healthpb.HealthCheckResponse_SERVING)
+	db.healthy.Store(false)
+	h.CheckNow(ctx)
+	expect(healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+func get(t *testing.T, handler http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestHTTPHandlers(t *testing.T) {
+	h, _, db, orders := newHealth(t)
+	handler := h.Handler()
+
+	if w := get(t, handler, "/livez"); w.Code != http.StatusOK {
+		t.Fatalf("/livez: %d", w.Code)
+	}
+	if w := get(t, handler, "/readyz"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("/readyz до проверок: %d, ожидался 503", w.Code)
+	}
+
+	db.healthy.Store(true)
+	h.CheckNow(context.Background())
+	w := get(t, handler, "/healthz")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("/healthz с непройденной проверкой: %d, ожидался 503", w.Code)
+	}
+	var report struct {
+		Status   string            `json:"status"`
+		Services map[string]string `json:"services"`
+		Checks   []Result          `json:"checks"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != "NOT_SERVING" || report.Services[usersService] != "SERVING" || report.Services[ordersService] != "NOT_SERVING" {
+		t.Fatalf("отчет /healthz: %+v", report)
+	}
+	if len(report.Checks) != 2 || !report.Checks[0].Healthy || report.Checks[1].Healthy || report.Checks[1].Error == "" {
+		t.Fatalf("проверки в /healthz: %+v", report.Checks)
+	}
+
+	orders.healthy.Store(true)
+	h.CheckNow(context.Background())
+	if w := get(t, handler, "/readyz"); w.Code != http.StatusOK {
+		t.Fatalf("/readyz: %d, ожидался 200", w.Code)
+	}
+	if w := get(t, handler, "/healthz"); w.Code != http.StatusOK {
+		t.Fatalf("/healthz: %d, ожидался 200", w.Code)
+	}
+
+	h.Shutdown()
+	for _, path := range []string{"/livez", "/readyz", "/healthz"} {
+		if w := get(t, handler, path); w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%s после Shutdown: %d, ожидался 503", path, w.Code)
+		}
+	}
+}
+
+var _ GRPCHealthClient = (*grpcclient.Client)(nil)
+
+// fakeClient — GRPCHealthClient, запоминающий запрошенный сервис
+type fakeClient struct {
+	readyErr error
+	checked  []string
+}
+
+func (c *fakeClient) WaitReady(context.Context) error { return c.readyErr }
+
+func (c *fakeClient) CheckHealth(_ context.Context, service string) error {
+	c.checked = append(c.checked, service)
+	return nil
+}
+
+func TestGRPCClientCheck(t *testing.T) {
+	client := &fakeClient{}
+	if err := GRPCClient(client, "").Check(context.Background()); err != nil || len(client.checked) != 0 {
+		t.Fatalf("без сервиса: ошибка %v, запрошены %v", err, client.checked)
+	}
+	if err := GRPCClient(client, usersService).Check(context.Background()); err != nil || len(client.checked) != 1 || client.checked[0] != usersService {
+		t.Fatalf("с сервисом: ошибка %v, запрошены %v", err, client.checked)
+	}
+
+	client.readyErr = errors.New("нет соединения")
+	if err := GRPCClient(client, usersService).Check(context.Background()); err == nil {
+		t.Fatal("ожидалась ошибка соединения")
+	}
+}
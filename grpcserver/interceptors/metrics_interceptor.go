@@ -32,3 +32,53 @@ func MetricsMiddleware(
 		return handler(ctx, req)
 	}
 }
+
+func StreamMetricsMiddleware(
+	serviceName string,
+) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		methodName := info.FullMethod
+
+		metrics.RequestCount.WithLabelValues(serviceName, methodName).Inc()
+
+		// Таймер для измерения длительности потока
+		timer := prometheus.NewTimer(
+			metrics.StreamDuration.WithLabelValues(serviceName, methodName),
+		)
+		defer timer.ObserveDuration()
+
+		return handler(srv, &metricsServerStream{
+			ServerStream: ss,
+			sent:         metrics.StreamMessagesSent.WithLabelValues(serviceName, methodName),
+			received:     metrics.StreamMessagesReceived.WithLabelValues(serviceName, methodName),
+		})
+	}
+}
+
+// metricsServerStream считает сообщения, прошедшие через поток
+type metricsServerStream struct {
+	grpc.ServerStream
+	sent     prometheus.Counter
+	received prometheus.Counter
+}
+
+func (s *metricsServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Inc()
+	}
+	return err
+}
+
+func (s *metricsServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received.Inc()
+	}
+	return err
+}
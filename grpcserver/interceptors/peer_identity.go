@@ -0,0 +1,98 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// ForwardedClientCertKey is the metadata key the HTTP gateway uses to pass
+// the base64-encoded DER certificate of its TLS client to the gRPC server.
+const ForwardedClientCertKey = "x-forwarded-client-cert"
+
+// PeerIdentity describes the TLS client certificate of the caller.
+type PeerIdentity struct {
+	Certificate *x509.Certificate
+	// Forwarded is true when the certificate was presented to the HTTP gateway
+	// and forwarded over the loopback connection.
+	Forwarded bool
+}
+
+// CommonName returns the subject common name of the peer certificate.
+func (p PeerIdentity) CommonName() string {
+	return p.Certificate.Subject.CommonName
+}
+
+type peerIdentityKey struct{}
+
+// PeerIdentityFromContext returns the identity stored by PeerIdentityMiddleware.
+func PeerIdentityFromContext(ctx context.Context) (PeerIdentity, bool) {
+	id, ok := ctx.Value(peerIdentityKey{}).(PeerIdentity)
+	return id, ok
+}
+
+// PeerIdentityMiddleware stores the verified client certificate in the request context.
+// Requests arriving from the gateway (authenticated with gatewayCert) carry the identity
+// of the original HTTP client in ForwardedClientCertKey metadata.
+func PeerIdentityMiddleware(gatewayCert []byte) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		return handler(withPeerIdentity(ctx, gatewayCert), req)
+	}
+}
+
+// PeerIdentityStreamMiddleware is the streaming counterpart of PeerIdentityMiddleware.
+func PeerIdentityStreamMiddleware(gatewayCert []byte) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &contextServerStream{
+			ServerStream: ss,
+			ctx:          withPeerIdentity(ss.Context(), gatewayCert),
+		})
+	}
+}
+
+func withPeerIdentity(ctx context.Context, gatewayCert []byte) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		return ctx
+	}
+
+	cert := tlsInfo.State.PeerCertificates[0]
+	if !bytes.Equal(cert.Raw, gatewayCert) {
+		return context.WithValue(ctx, peerIdentityKey{}, PeerIdentity{Certificate: cert})
+	}
+
+	// The caller is the gateway: use the certificate it forwarded, if any.
+	values := metadata.ValueFromIncomingContext(ctx, ForwardedClientCertKey)
+	if len(values) != 1 {
+		return ctx
+	}
+	der, err := base64.StdEncoding.DecodeString(values[0])
+	if err != nil {
+		return ctx
+	}
+	forwarded, err := x509.ParseCertificate(der)
+	if err != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, peerIdentityKey{}, PeerIdentity{Certificate: forwarded, Forwarded: true})
+}
@@ -0,0 +1,78 @@
+package interceptors
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	clientinterceptors "github.com/arrowwhi/go-utils/grpcclient/interceptors"
+	"github.com/arrowwhi/go-utils/grpcserver/metrics"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PanicHandler вызывается после восстановления после паники в обработчике.
+// Возвращаемая ошибка отправляется клиенту; nil — клиент получает codes.Internal.
+type PanicHandler func(ctx context.Context, method string, p interface{}) error
+
+// RecoveryMiddleware перехватывает панику в обработчике унарного вызова:
+// пишет в лог стек, метод и x-request-id, увеличивает grpc_panics_total
+// и завершает вызов с codes.Internal или ошибкой из handler.
+func RecoveryMiddleware(serviceName string, logger *zap.Logger, panicHandler PanicHandler) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recoverPanic(ctx, serviceName, info.FullMethod, p, logger, panicHandler)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecoveryMiddleware — RecoveryMiddleware для потоковых вызовов
+func StreamRecoveryMiddleware(serviceName string, logger *zap.Logger, panicHandler PanicHandler) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recoverPanic(ss.Context(), serviceName, info.FullMethod, p, logger, panicHandler)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+// recoverPanic обрабатывает перехваченную панику и возвращает ошибку для клиента
+func recoverPanic(ctx context.Context, serviceName, method string, p interface{}, logger *zap.Logger, panicHandler PanicHandler) error {
+	metrics.PanicCount.WithLabelValues(serviceName, method).Inc()
+
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.String("panic", fmt.Sprint(p)),
+		zap.ByteString("stack", debug.Stack()),
+	}
+	if requestID, ok := clientinterceptors.RequestIDFromContext(ctx); ok {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
+	logger.Error("panic in gRPC handler", fields...)
+
+	if panicHandler != nil {
+		if err := panicHandler(ctx, method, p); err != nil {
+			return err
+		}
+	}
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,109 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	clientinterceptors "github.com/arrowwhi/go-utils/grpcclient/interceptors"
+	"github.com/arrowwhi/go-utils/grpcserver/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const panicMethod = "/test.Service/Panic"
+
+// panicStream — grpc.ServerStream с заданным контекстом
+type panicStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s panicStream) Context() context.Context { return s.ctx }
+
+func incomingContext() context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(clientinterceptors.RequestIDHeader, "req-1"))
+}
+
+// recoverUnary вызывает паникующий унарный обработчик через RecoveryMiddleware
+func recoverUnary(service string, logger *zap.Logger, panicHandler PanicHandler) error {
+	interceptor := RecoveryMiddleware(service, logger, panicHandler)
+	_, err := interceptor(incomingContext(), nil, &grpc.UnaryServerInfo{FullMethod: panicMethod}, func(context.Context, interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	return err
+}
+
+// recoverStream вызывает паникующий потоковый обработчик через StreamRecoveryMiddleware
+func recoverStream(service string, logger *zap.Logger, panicHandler PanicHandler) error {
+	interceptor := StreamRecoveryMiddleware(service, logger, panicHandler)
+	return interceptor(nil, panicStream{ctx: incomingContext()}, &grpc.StreamServerInfo{FullMethod: panicMethod}, func(interface{}, grpc.ServerStream) error {
+		panic("boom")
+	})
+}
+
+func TestRecovery(t *testing.T) {
+	for name, recoverCall := range map[string]func(string, *zap.Logger, PanicHandler) error{
+		"unary":  recoverUnary,
+		"stream": recoverStream,
+	} {
+		t.Run(name, func(t *testing.T) {
+			service := "recovery-" + name
+			core, logs := observer.New(zap.ErrorLevel)
+
+			err := recoverCall(service, zap.New(core), nil)
+			if status.Code(err) != codes.Internal {
+				t.Fatalf("код %v, ожидался Internal", status.Code(err))
+			}
+			if n := testutil.ToFloat64(metrics.PanicCount.WithLabelValues(service, panicMethod)); n != 1 {
+				t.Fatalf("grpc_panics_total = %v, ожидалось 1", n)
+			}
+
+			entries := logs.All()
+			if len(entries) != 1 {
+				t.Fatalf("записей в логе %d, ожидалась 1", len(entries))
+			}
+			fields := entries[0].ContextMap()
+			if fields["method"] != panicMethod || fields["panic"] != "boom" || fields["request_id"] != "req-1" || fields["stack"] == "" {
+				t.Fatalf("поля записи лога: %v", fields)
+			}
+		})
+	}
+}
+
+func TestRecoveryPanicHandler(t *testing.T) {
+	for name, recoverCall := range map[string]func(string, *zap.Logger, PanicHandler) error{
+		"unary":  recoverUnary,
+		"stream": recoverStream,
+	} {
+		t.Run(name, func(t *testing.T) {
+			service := "panic-handler-" + name
+			var recovered interface{}
+			override := func(ctx context.Context, method string, p interface{}) error {
+				recovered = p
+				return status.Error(codes.Unavailable, "try later")
+			}
+
+			err := recoverCall(service, zap.NewNop(), override)
+			if status.Code(err) != codes.Unavailable {
+				t.Fatalf("код %v, ожидалась ошибка PanicHandler", status.Code(err))
+			}
+			if recovered != "boom" {
+				t.Fatalf("PanicHandler получил %v", recovered)
+			}
+
+			// PanicHandler, вернувший nil, не меняет ответ по умолчанию
+			err = recoverCall(service, zap.NewNop(), func(context.Context, string, interface{}) error { return nil })
+			if status.Code(err) != codes.Internal {
+				t.Fatalf("код %v, ожидался Internal", status.Code(err))
+			}
+			if n := testutil.ToFloat64(metrics.PanicCount.WithLabelValues(service, panicMethod)); n != 2 {
+				t.Fatalf("grpc_panics_total = %v, ожидалось 2", n)
+			}
+		})
+	}
+}
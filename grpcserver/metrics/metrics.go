@@ -27,6 +27,43 @@ var (
 		},
 		[]string{"service", "method"}, // Метки
 	)
+
+	// StreamMessagesSent Счетчик сообщений, отправленных сервером в потоковых вызовах
+	StreamMessagesSent = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_stream_messages_sent_total",
+			Help: "Количество сообщений, отправленных в gRPC потоках",
+		},
+		[]string{"service", "method"},
+	)
+
+	// StreamMessagesReceived Счетчик сообщений, полученных сервером в потоковых вызовах
+	StreamMessagesReceived = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_stream_messages_received_total",
+			Help: "Количество сообщений, полученных в gRPC потоках",
+		},
+		[]string{"service", "method"},
+	)
+
+	// StreamDuration Гистограмма длительности потоковых вызовов
+	StreamDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_stream_duration_seconds",
+			Help:    "Длительность gRPC потоков в секундах",
+			Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300, 900, 3600},
+		},
+		[]string{"service", "method"},
+	)
+
+	// PanicCount Счетчик паник, перехваченных в обработчиках gRPC вызовов
+	PanicCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_panics_total",
+			Help: "Количество паник в обработчиках gRPC вызовов",
+		},
+		[]string{"service", "method"},
+	)
 )
 
 // InitMetrics Функция инициализации метрик
@@ -43,6 +80,19 @@ func InitMetrics(zapLogger *zap.Logger) error {
 		return fmt.Errorf("failed to register RequestDuration: %w", err)
 	}
 
+	// Регистрируем метрики потоковых вызовов и счетчик паник
+	for name, collector := range map[string]prometheus.Collector{
+		"StreamMessagesSent":     StreamMessagesSent,
+		"StreamMessagesReceived": StreamMessagesReceived,
+		"StreamDuration":         StreamDuration,
+		"PanicCount":             PanicCount,
+	} {
+		if err := prometheus.Register(collector); err != nil {
+			zapLogger.Error("error to register "+name, zap.Error(err))
+			return fmt.Errorf("failed to register %s: %w", name, err)
+		}
+	}
+
 	return nil
 }
 
@@ -2,12 +2,25 @@ package grpcserver
 
 import (
 	"github.com/arrowwhi/go-utils/grpcserver/handler_adapter"
+	"github.com/arrowwhi/go-utils/grpcserver/health"
+	"github.com/arrowwhi/go-utils/grpcserver/interceptors"
 	"google.golang.org/grpc"
 )
 
 type options struct {
-	adapters                    []handler_adapter.ImplementationAdapter
-	grpcUnaryServerInterceptors []grpc.UnaryServerInterceptor
+	adapters                     []handler_adapter.ImplementationAdapter
+	grpcUnaryServerInterceptors  []grpc.UnaryServerInterceptor
+	grpcStreamServerInterceptors []grpc.StreamServerInterceptor
+	grpcServerOptions            []grpc.ServerOption
+	healthChecks                 []healthCheck
+	panicHandler                 interceptors.PanicHandler
+	disablePanicRecovery         bool
+}
+
+type healthCheck struct {
+	name     string
+	checker  health.Checker
+	services []string
 }
 
 type option func(o *options)
@@ -24,6 +37,42 @@ func WithGrpcUnaryServerInterceptors(grpcUnaryServerInterceptors ...grpc.UnarySe
 	})
 }
 
+func WithGrpcStreamServerInterceptors(grpcStreamServerInterceptors ...grpc.StreamServerInterceptor) EntrypointOption {
+	return option(func(o *options) {
+		o.grpcStreamServerInterceptors = append(o.grpcStreamServerInterceptors, grpcStreamServerInterceptors...)
+	})
+}
+
+// WithGrpcServerOptions добавляет grpc.ServerOption; они применяются после опций из конфигурации
+// и могут их переопределить
+func WithGrpcServerOptions(grpcServerOptions ...grpc.ServerOption) EntrypointOption {
+	return option(func(o *options) {
+		o.grpcServerOptions = append(o.grpcServerOptions, grpcServerOptions...)
+	})
+}
+
+// WithHealthCheck добавляет проверку состояния для grpc.health.v1 и HTTP /healthz, /readyz.
+// services — сервисы, статус которых зависит от проверки; если не указаны — все сервисы:
+//
+//	grpcserver.WithHealthCheck("postgres", health.Ping(db))
+//	grpcserver.WithHealthCheck("users", health.GRPCClient(usersClient, ""), "orders.v1.OrderService")
+func WithHealthCheck(name string, checker health.Checker, services ...string) EntrypointOption {
+	return option(func(o *options) {
+		o.healthChecks = append(o.healthChecks, healthCheck{name: name, checker: checker, services: services})
+	})
+}
+
+// WithPanicHandler задает обработчик паник в обработчиках вызовов, например для отправки
+// в систему мониторинга ошибок. Возвращенная ошибка отправляется клиенту вместо codes.Internal.
+func WithPanicHandler(panicHandler interceptors.PanicHandler) EntrypointOption {
+	return option(func(o *options) { o.panicHandler = panicHandler })
+}
+
+// WithoutPanicRecovery отключает перехват паник: паника в обработчике завершает процесс
+func WithoutPanicRecovery() EntrypointOption {
+	return option(func(o *options) { o.disablePanicRecovery = true })
+}
+
 type EntrypointOption interface {
 	apply(*options)
 }
@@ -5,11 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"github.com/arrowwhi/go-utils/grpcserver/gateway"
+	"github.com/arrowwhi/go-utils/grpcserver/health"
 	"github.com/arrowwhi/go-utils/grpcserver/interceptors"
 	"github.com/arrowwhi/go-utils/grpcserver/metrics"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 	"net"
+	"net/http"
 	"sync"
 
 	"google.golang.org/grpc"
@@ -23,6 +26,8 @@ type Server struct {
 	logger      *zap.Logger
 	grpcServer  *grpc.Server
 	config      grpc_config.Config
+	health      *health.Health
+	probes      *http.Server
 }
 
 func NewServer(serverConfig grpc_config.Config, logger *zap.Logger, opts ...EntrypointOption) (*Server, error) {
@@ -32,20 +37,75 @@ func NewServer(serverConfig grpc_config.Config, logger *zap.Logger, opts ...Entr
 		opt.apply(&o)
 	}
 
+	h := health.New(health.Config{
+		Interval: serverConfig.HealthCheckInterval,
+		Timeout:  serverConfig.HealthCheckTimeout,
+	})
+	for _, c := range o.healthChecks {
+		h.AddCheck(c.name, c.checker, c.services...)
+	}
+
 	return &Server{
 		logger:  logger,
 		options: o,
 		config:  serverConfig,
+		health:  h,
 	}, nil
 }
 
+// Health возвращает проверки состояния сервера, например чтобы добавить проверку
+// до вызова Start или узнать статус сервиса
+func (s *Server) Health() *health.Health {
+	return s.health
+}
+
 // Start запускает gRPC сервер и начинает прослушивание входящих запросов.
 func (s *Server) Start(ctx context.Context) error {
-	// Interceptors
-	ints := []grpc.ServerOption{grpc.ChainUnaryInterceptor(interceptors.MetricsMiddleware(s.config.ServiceName))}
+	// Interceptors. Panic recovery goes first so it also covers the interceptors below.
+	var ints []grpc.ServerOption
+	if !s.disablePanicRecovery {
+		ints = append(ints,
+			grpc.ChainUnaryInterceptor(interceptors.RecoveryMiddleware(s.config.ServiceName, s.logger, s.panicHandler)),
+			grpc.ChainStreamInterceptor(interceptors.StreamRecoveryMiddleware(s.config.ServiceName, s.logger, s.panicHandler)),
+		)
+	}
+	ints = append(ints,
+		grpc.ChainUnaryInterceptor(interceptors.MetricsMiddleware(s.config.ServiceName)),
+		grpc.ChainStreamInterceptor(interceptors.StreamMetricsMiddleware(s.config.ServiceName)),
+	)
+
+	// TLS for the gRPC listener and the gateway
+	var tlsMaterial *serverTLS
+	if s.config.TLSEnabled() {
+		var err error
+		tlsMaterial, err = newServerTLS(s.config)
+		if err != nil {
+			return fmt.Errorf("configure TLS: %w", err)
+		}
+		ints = append(ints,
+			grpc.Creds(credentials.NewTLS(tlsMaterial.config)),
+			grpc.ChainUnaryInterceptor(interceptors.PeerIdentityMiddleware(tlsMaterial.leaf)),
+			grpc.ChainStreamInterceptor(interceptors.PeerIdentityStreamMiddleware(tlsMaterial.leaf)),
+		)
+	}
+
+	// Response compression
+	compression, err := compressionOptions(s.config.Compression)
+	if err != nil {
+		return fmt.Errorf("configure compression: %w", err)
+	}
+	ints = append(ints, compression...)
+
 	for _, v := range s.grpcUnaryServerInterceptors {
 		ints = append(ints, grpc.ChainUnaryInterceptor(v))
 	}
+	for _, v := range s.grpcStreamServerInterceptors {
+		ints = append(ints, grpc.ChainStreamInterceptor(v))
+	}
+
+	// Keepalive, message sizes and user-supplied server options
+	ints = append(ints, transportOptions(s.config)...)
+	ints = append(ints, s.grpcServerOptions...)
 
 	// Create gRPC server
 	s.grpcServer = grpc.NewServer(ints...)
@@ -75,11 +135,38 @@ func (s *Server) Start(ctx context.Context) error {
 	// Register reflection service on gRPC server.
 	reflection.Register(s.grpcServer)
 
+	// Register grpc.health.v1 and run checks until shutdown
+	s.health.Register(s.grpcServer)
+	go s.health.Run(ctx)
+
+	// Serve the probes on a plain HTTP listener: kubelet cannot present a client certificate
+	// to the gateway when TLS_REQUIRE_CLIENT_CERT is set
+	var probeListener net.Listener
+	if s.config.HealthPort != "" {
+		probeListener, err = net.Listen("tcp", fmt.Sprintf(":%s", s.config.HealthPort))
+		if err != nil {
+			return fmt.Errorf("failed to listen on health port: %w", err)
+		}
+		s.probes = &http.Server{Handler: s.health.Handler()}
+	}
+
 	// Prepare HTTP gateway options
 	var gatewayOptions []gateway.Option
 	for _, v := range s.adapters {
 		gatewayOptions = append(gatewayOptions, gateway.WithHandler(v.RegisterHandler))
 	}
+	gatewayOptions = append(gatewayOptions,
+		gateway.WithDialOptions(gatewayCallOptions(s.config)...),
+		gateway.WithHTTPHandler("/healthz", s.health.Healthz()),
+		gateway.WithHTTPHandler("/readyz", s.health.Readyz()),
+		gateway.WithHTTPHandler("/livez", s.health.Livez()),
+	)
+	if tlsMaterial != nil {
+		gatewayOptions = append(gatewayOptions,
+			gateway.WithTLSConfig(tlsMaterial.config),
+			gateway.WithDialOptions(grpc.WithTransportCredentials(tlsMaterial.loopback)),
+		)
+	}
 
 	// Create the gateway
 	gw := gateway.NewGateway(
@@ -93,12 +180,15 @@ func (s *Server) Start(ctx context.Context) error {
 	wg.Add(2) // We'll have two goroutines: one for gRPC server and one for HTTP gateway
 
 	// Channel to capture errors
-	errChan := make(chan error, 2)
+	errChan := make(chan error, 3)
 
 	// Start the gRPC server in a goroutine
 	go func() {
 		defer wg.Done()
-		s.logger.Info("Starting gRPC server", zap.String("address", s.config.GRPCPort))
+		s.logger.Info("Starting gRPC server",
+			zap.String("address", s.config.GRPCPort),
+			zap.Bool("tls", tlsMaterial != nil),
+		)
 		if err := s.grpcServer.Serve(listener); err != nil && err != grpc.ErrServerStopped {
 			s.logger.Error("Failed to serve gRPC", zap.Error(err))
 			errChan <- err
@@ -121,6 +211,16 @@ func (s *Server) Start(ctx context.Context) error {
 		s.logger.Info("HTTP gateway stopped")
 	}()
 
+	if s.probes != nil {
+		go func() {
+			s.logger.Info("Starting health probes", zap.String("address", s.config.HealthPort))
+			if err := s.probes.Serve(probeListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.logger.Error("Failed to serve health probes", zap.Error(err))
+				errChan <- err
+			}
+		}()
+	}
+
 	// Listen for context cancellation or errors
 	select {
 	case <-ctx.Done():
@@ -141,7 +241,11 @@ func (s *Server) Start(ctx context.Context) error {
 // Stop корректно завершает работу gRPC сервера.
 func (s *Server) Stop() {
 	s.logger.Info("Stopping gRPC server...")
+	s.health.Shutdown()
 	s.grpcServer.GracefulStop()
+	if s.probes != nil {
+		_ = s.probes.Close()
+	}
 	// If you have a mechanism to stop the HTTP gateway, you should call it here
 	// e.g., gw.Stop()
 	s.logger.Info("gRPC server stopped")
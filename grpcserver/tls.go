@@ -0,0 +1,122 @@
+package grpcserver
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+
+	"github.com/arrowwhi/go-utils/grpcserver/grpc_config"
+)
+
+// serverTLS holds the TLS material shared by the gRPC listener and the HTTP gateway.
+type serverTLS struct {
+	// config is used by both the gRPC listener and the gateway http.Server.
+	config *tls.Config
+	// loopback is used by the gateway to dial the gRPC server.
+	loopback credentials.TransportCredentials
+	// leaf is the raw server certificate; connections presenting it are the gateway itself.
+	leaf []byte
+}
+
+func newServerTLS(cfg grpc_config.Config) (*serverTLS, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load server certificate: %w", err)
+	}
+	if len(cert.Certificate) == 0 {
+		return nil, errors.New("server certificate file contains no certificates")
+	}
+	leaf := cert.Certificate[0]
+
+	var clientCAs *x509.CertPool
+	if cfg.TLSClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read client CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("client CA file %s contains no certificates", cfg.TLSClientCAFile)
+		}
+	}
+	if cfg.TLSRequireClientCert && clientCAs == nil {
+		return nil, errors.New("TLS_REQUIRE_CLIENT_CERT is set but TLS_CLIENT_CA_FILE is empty")
+	}
+
+	serverConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    clientCAs,
+	}
+
+	// Client certificates are verified in VerifyConnection rather than by crypto/tls,
+	// so the gateway can authenticate to the gRPC listener with the server certificate.
+	switch {
+	case cfg.TLSRequireClientCert:
+		serverConfig.ClientAuth = tls.RequireAnyClientCert
+	case clientCAs != nil:
+		serverConfig.ClientAuth = tls.RequestClientCert
+	default:
+		serverConfig.ClientAuth = tls.NoClientCert
+	}
+	if clientCAs != nil {
+		serverConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyClientCert(cs, clientCAs, leaf)
+		}
+	}
+
+	loopbackConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// The gateway pins the server certificate instead of verifying its hostname,
+		// because the certificate is not necessarily issued for localhost.
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 || !bytes.Equal(rawCerts[0], leaf) {
+				return errors.New("gateway loopback: unexpected server certificate")
+			}
+			return nil
+		},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &cert, nil
+		},
+	}
+
+	return &serverTLS{
+		config:   serverConfig,
+		loopback: credentials.NewTLS(loopbackConfig),
+		leaf:     leaf,
+	}, nil
+}
+
+// verifyClientCert verifies the peer certificate chain against the client CA pool.
+// Connections presenting the server's own certificate are accepted as gateway loopback.
+func verifyClientCert(cs tls.ConnectionState, clientCAs *x509.CertPool, leaf []byte) error {
+	if len(cs.PeerCertificates) == 0 {
+		// crypto/tls has already rejected the handshake if a certificate is required.
+		return nil
+	}
+
+	peerCert := cs.PeerCertificates[0]
+	if bytes.Equal(peerCert.Raw, leaf) {
+		return nil
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := peerCert.Verify(x509.VerifyOptions{
+		Roots:         clientCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return fmt.Errorf("verify client certificate: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arrowwhi/go-utils/grpcserver/grpc_config"
+	"github.com/arrowwhi/go-utils/grpcserver/interceptors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// testCA is a certificate authority generated for a single test.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key for localhost signed by the CA.
+func (ca *testCA) issue(t *testing.T, commonName string) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// keyPair returns a tls.Certificate issued by the CA.
+func (ca *testCA) keyPair(t *testing.T, commonName string) tls.Certificate {
+	t.Helper()
+	pair, err := tls.X509KeyPair(ca.issue(t, commonName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pair
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// identityService is a gRPC service that reports the caller identity seen by the server.
+type identityService struct {
+	identities chan *interceptors.PeerIdentity
+}
+
+var identityServiceDesc = grpc.ServiceDesc{
+	ServiceName: "test.Identity",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Whoami",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := &emptypb.Empty{}
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+				var identity *interceptors.PeerIdentity
+				if id, ok := interceptors.PeerIdentityFromContext(ctx); ok {
+					identity = &id
+				}
+				srv.(*identityService).identities <- identity
+				return &emptypb.Empty{}, nil
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Identity/Whoami"}, handler)
+		},
+	}},
+}
+
+// startMTLSServer starts a gRPC server configured like Server.Start with TLS_REQUIRE_CLIENT_CERT.
+func startMTLSServer(t *testing.T, ca *testCA) (string, *serverTLS, *identityService) {
+	t.Helper()
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.pem")
+	certPEM, keyPEM := ca.issue(t, "server")
+	writeFile(t, certFile, certPEM)
+	writeFile(t, keyFile, keyPEM)
+	writeFile(t, caFile, ca.pem)
+
+	material, err := newServerTLS(grpc_config.Config{
+		TLSCertFile:          certFile,
+		TLSKeyFile:           keyFile,
+		TLSClientCAFile:      caFile,
+		TLSRequireClientCert: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := &identityService{identities: make(chan *interceptors.PeerIdentity, 1)}
+	srv := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(material.config)),
+		grpc.ChainUnaryInterceptor(interceptors.PeerIdentityMiddleware(material.leaf)),
+	)
+	srv.RegisterService(&identityServiceDesc, svc)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String(), material, svc
+}
+
+// whoami calls the identity service and returns the identity the server saw.
+func whoami(t *testing.T, addr string, svc *identityService, creds credentials.TransportCredentials, md metadata.MD) (*interceptors.PeerIdentity, error) {
+	t.Helper()
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if md != nil {
+		ctx = metadata.NewOutgoingContext(ctx, md)
+	}
+	if err := conn.Invoke(ctx, "/test.Identity/Whoami", &emptypb.Empty{}, &emptypb.Empty{}); err != nil {
+		return nil, err
+	}
+	return <-svc.identities, nil
+}
+
+// clientCreds returns mTLS client credentials trusting ca and presenting cert.
+func clientCreds(ca *testCA, cert tls.Certificate) credentials.TransportCredentials {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	return credentials.NewTLS(&tls.Config{
+		RootCAs:      roots,
+		ServerName:   "localhost",
+		Certificates: []tls.Certificate{cert},
+	})
+}
+
+func forwardedHeader(cert tls.Certificate) metadata.MD {
+	return metadata.Pairs(interceptors.ForwardedClientCertKey, base64.StdEncoding.EncodeToString(cert.Certificate[0]))
+}
+
+func TestMTLSRejectsUntrustedClientCert(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	addr, _, svc := startMTLSServer(t, ca)
+
+	// The certificate is issued by a CA the server does not trust
+	untrusted := newTestCA(t, "other-ca").keyPair(t, "intruder")
+	if _, err := whoami(t, addr, svc, clientCreds(ca, untrusted), nil); err == nil {
+		t.Fatal("expected the handshake with an untrusted client certificate to fail")
+	}
+}
+
+func TestMTLSPeerIdentity(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	addr, _, svc := startMTLSServer(t, ca)
+
+	id, err := whoami(t, addr, svc, clientCreds(ca, ca.keyPair(t, "client")), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == nil || id.CommonName() != "client" || id.Forwarded {
+		t.Fatalf("unexpected identity %+v", id)
+	}
+}
+
+func TestForwardedClientCertIgnoredFromNonGatewayPeer(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	addr, _, svc := startMTLSServer(t, ca)
+
+	// A trusted client claims to forward another identity; only the gateway may do that
+	spoofed := forwardedHeader(ca.keyPair(t, "admin"))
+	id, err := whoami(t, addr, svc, clientCreds(ca, ca.keyPair(t, "client")), spoofed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == nil || id.CommonName() != "client" || id.Forwarded {
+		t.Fatalf("forwarded certificate from a non-gateway peer was trusted: %+v", id)
+	}
+}
+
+func TestForwardedClientCertFromGateway(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	addr, material, svc := startMTLSServer(t, ca)
+
+	// The gateway dials with the loopback credentials and forwards the verified HTTP client
+	id, err := whoami(t, addr, svc, material.loopback, forwardedHeader(ca.keyPair(t, "http-client")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == nil || id.CommonName() != "http-client" || !id.Forwarded {
+		t.Fatalf("unexpected forwarded identity %+v", id)
+	}
+
+	// Without a forwarded certificate the gateway call carries no identity
+	id, err = whoami(t, addr, svc, material.loopback, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != nil {
+		t.Fatalf("gateway call without a forwarded certificate got identity %+v", id)
+	}
+}
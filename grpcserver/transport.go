@@ -0,0 +1,83 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/arrowwhi/go-utils/grpcserver/grpc_config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip" // регистрирует компрессор gzip для сжатых запросов и COMPRESSION=gzip
+	"google.golang.org/grpc/keepalive"
+)
+
+// transportOptions возвращает параметры keepalive и ограничения размера сообщений из конфигурации
+func transportOptions(cfg grpc_config.Config) []grpc.ServerOption {
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     cfg.MaxConnectionIdle,
+			MaxConnectionAge:      cfg.MaxConnectionAge,
+			MaxConnectionAgeGrace: cfg.MaxConnectionAgeGrace,
+			Time:                  cfg.KeepaliveTime,
+			Timeout:               cfg.KeepaliveTimeout,
+		}),
+		// Пинги клиентов чаще MinTime считаются злоупотреблением, и сервер закрывает соединение
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.KeepaliveMinTime,
+			PermitWithoutStream: cfg.KeepalivePermitWithoutStream,
+		}),
+	}
+
+	if cfg.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if cfg.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(cfg.MaxSendMsgSize))
+	}
+	return opts
+}
+
+// compressionOptions возвращает интерцепторы, сжимающие ответы компрессором name.
+// Без них ответ сжимается тем же компрессором, что и запрос, а ответы на несжатые запросы не сжимаются.
+// Компрессор применяется, только если клиент указал его в grpc-accept-encoding.
+func compressionOptions(name string) ([]grpc.ServerOption, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if encoding.GetCompressor(name) == nil {
+		return nil, fmt.Errorf("compressor %q is not registered", name)
+	}
+
+	setCompressor := func(ctx context.Context) {
+		if supported, err := grpc.ClientSupportedCompressors(ctx); err == nil && slices.Contains(supported, name) {
+			_ = grpc.SetSendCompressor(ctx, name)
+		}
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			setCompressor(ctx)
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			setCompressor(ss.Context())
+			return handler(srv, ss)
+		}),
+	}, nil
+}
+
+// gatewayCallOptions согласует ограничения размера сообщений шлюза с сервером:
+// шлюз принимает то, что отправляет сервер, и отправляет то, что сервер принимает
+func gatewayCallOptions(cfg grpc_config.Config) []grpc.DialOption {
+	var opts []grpc.CallOption
+	if cfg.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxCallRecvMsgSize(cfg.MaxSendMsgSize))
+	}
+	if cfg.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxCallSendMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if len(opts) == 0 {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithDefaultCallOptions(opts...)}
+}
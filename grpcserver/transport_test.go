@@ -0,0 +1,284 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arrowwhi/go-utils/grpcserver/grpc_config"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+// startTransportServer запускает сервер grpc.health.v1 с параметрами транспорта из cfg
+func startTransportServer(t *testing.T, cfg grpc_config.Config) string {
+	t.Helper()
+	compression, err := compressionOptions(cfg.Compression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer(append(transportOptions(cfg), compression...)...)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+func dial(t *testing.T, addr string, opts ...grpc.DialOption) *grpc.ClientConn {
+	t.Helper()
+	conn, err := grpc.NewClient(addr, append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func check(ctx context.Context, conn *grpc.ClientConn, service string) error {
+	_, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	return err
+}
+
+func TestMaxRecvMsgSize(t *testing.T) {
+	addr := startTransportServer(t, grpc_config.Config{MaxRecvMsgSize: 1024, KeepaliveTime: time.Hour, KeepaliveTimeout: time.Second})
+	conn := dial(t, addr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Небольшой запрос проходит: сервис не зарегистрирован в health, но размер допустим
+	if err := check(ctx, conn, "small"); status.Code(err) != codes.NotFound {
+		t.Fatalf("небольшой запрос: %v, ожидался NotFound", err)
+	}
+	if err := check(ctx, conn, strings.Repeat("x", 2048)); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("запрос больше MaxRecvMsgSize: %v, ожидался ResourceExhausted", err)
+	}
+}
+
+// waitState ждет изменения состояния соединения из from; false — состояние не изменилось за timeout
+func waitState(conn *grpc.ClientConn, from connectivity.State, timeout time.Duration) (connectivity.State, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if !conn.WaitForStateChange(ctx, from) {
+		return from, false
+	}
+	return conn.GetState(), true
+}
+
+func TestMaxConnectionIdleClosesIdleConnection(t *testing.T) {
+	addr := startTransportServer(t, grpc_config.Config{
+		MaxConnectionIdle: 100 * time.Millisecond,
+		KeepaliveTime:     time.Hour,
+		KeepaliveTimeout:  time.Second,
+	})
+	conn := dial(t, addr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = check(ctx, conn, "")
+	if state := conn.GetState(); state != connectivity.Ready {
+		t.Fatalf("состояние после вызова: %s", state)
+	}
+
+	// Сервер отправляет GOAWAY простаивающему соединению, и канал уходит в IDLE
+	if state, changed := waitState(conn, connectivity.Ready, 2*time.Second); !changed || state != connectivity.Idle {
+		t.Fatalf("соединение не закрыто после MaxConnectionIdle: %s", state)
+	}
+}
+
+// pingConn — HTTP/2 соединение, отправляющее пинги с произвольной частотой.
+// Клиент grpc-go не пингует чаще раза в 10 секунд, поэтому политика проверяется на уровне фреймов.
+type pingConn struct {
+	conn   net.Conn
+	mu     sync.Mutex
+	framer *http2.Framer
+	acks   chan struct{}
+	goAway chan string
+	closed chan struct{}
+}
+
+func dialPing(t *testing.T, addr string) *pingConn {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	c := &pingConn{
+		conn:   conn,
+		framer: http2.NewFramer(conn, conn),
+		acks:   make(chan struct{}, 16),
+		goAway: make(chan string, 1),
+		closed: make(chan struct{}),
+	}
+	if _, err := conn.Write([]byte(http2.ClientPreface)); err != nil {
+		t.Fatal(err)
+	}
+	c.write(t, func(f *http2.Framer) error { return f.WriteSettings() })
+
+	go func() {
+		defer close(c.closed)
+		for {
+			frame, err := c.framer.ReadFrame()
+			if err != nil {
+				return
+			}
+			switch f := frame.(type) {
+			case *http2.SettingsFrame:
+				if !f.IsAck() {
+					c.mu.Lock()
+					_ = c.framer.WriteSettingsAck()
+					c.mu.Unlock()
+				}
+			case *http2.PingFrame:
+				if f.IsAck() {
+					c.acks <- struct{}{}
+				}
+			case *http2.GoAwayFrame:
+				if f.ErrCode == http2.ErrCodeEnhanceYourCalm {
+					c.goAway <- string(f.DebugData())
+				}
+			}
+		}
+	}()
+	return c
+}
+
+func (c *pingConn) write(t *testing.T, write func(f *http2.Framer) error) {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := write(c.framer); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (c *pingConn) ping(t *testing.T) {
+	t.Helper()
+	c.write(t, func(f *http2.Framer) error { return f.WritePing(false, [8]byte{1}) })
+}
+
+func TestKeepaliveEnforcementClosesAbusiveClient(t *testing.T) {
+	addr := startTransportServer(t, grpc_config.Config{
+		KeepaliveTime:                time.Hour,
+		KeepaliveMinTime:             time.Minute,
+		KeepalivePermitWithoutStream: true,
+	})
+	conn := dialPing(t, addr)
+
+	// Первый пинг допустим, два следующих чаще KEEPALIVE_MIN_TIME сервер прощает, на третьем отправляет GOAWAY
+	for i := 0; i < 4; i++ {
+		conn.ping(t)
+	}
+
+	select {
+	case debug := <-conn.goAway:
+		if debug != "too_many_pings" {
+			t.Fatalf("GOAWAY с причиной %q, ожидалась too_many_pings", debug)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("сервер не отправил GOAWAY ENHANCE_YOUR_CALM")
+	}
+	select {
+	case <-conn.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("сервер не закрыл соединение после GOAWAY")
+	}
+}
+
+func TestKeepaliveWithinPolicyKeepsConnection(t *testing.T) {
+	const minTime = 100 * time.Millisecond
+	addr := startTransportServer(t, grpc_config.Config{
+		KeepaliveTime:                time.Hour,
+		KeepaliveMinTime:             minTime,
+		KeepalivePermitWithoutStream: true,
+	})
+	conn := dialPing(t, addr)
+
+	for i := 0; i < 5; i++ {
+		conn.ping(t)
+		select {
+		case <-conn.acks:
+		case debug := <-conn.goAway:
+			t.Fatalf("пинг %d: сервер отправил GOAWAY %q", i+1, debug)
+		case <-conn.closed:
+			t.Fatalf("пинг %d: сервер закрыл соединение", i+1)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("пинг %d: нет ответа", i+1)
+		}
+		time.Sleep(minTime + 50*time.Millisecond)
+	}
+}
+
+// compressionStats запоминает компрессор, с которым сервер отправил ответ
+type compressionStats struct {
+	mu          sync.Mutex
+	compression string
+}
+
+func (s *compressionStats) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+func (s *compressionStats) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+func (s *compressionStats) HandleConn(context.Context, stats.ConnStats) {}
+func (s *compressionStats) HandleRPC(_ context.Context, rs stats.RPCStats) {
+	if h, ok := rs.(*stats.InHeader); ok {
+		s.mu.Lock()
+		s.compression = h.Compression
+		s.mu.Unlock()
+	}
+}
+
+func (s *compressionStats) last() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.compression
+}
+
+func TestResponseCompression(t *testing.T) {
+	for _, tc := range []struct {
+		compression string
+		want        string
+	}{
+		{compression: "gzip", want: "gzip"},
+		{compression: "", want: ""},
+	} {
+		addr := startTransportServer(t, grpc_config.Config{Compression: tc.compression, KeepaliveTime: time.Hour, KeepaliveTimeout: time.Second})
+		handler := &compressionStats{}
+		conn := dial(t, addr, grpc.WithStatsHandler(handler))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		// Запрос отправляется без сжатия
+		if err := check(ctx, conn, ""); err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+		if got := handler.last(); got != tc.want {
+			t.Errorf("COMPRESSION=%q: ответ сжат %q, ожидалось %q", tc.compression, got, tc.want)
+		}
+	}
+}
+
+func TestUnknownCompression(t *testing.T) {
+	if _, err := compressionOptions("zstd"); err == nil {
+		t.Fatal("ожидалась ошибка для незарегистрированного компрессора")
+	}
+}
@@ -46,3 +46,8 @@ func NewDatabase(cfg db_config.DBConfig, opts ...Option) (*Database, error) {
 func (db *Database) Close() {
 	db.Pool.Close()
 }
+
+// Ping проверяет доступность базы данных; подходит для проверок готовности сервиса
+func (db *Database) Ping(ctx context.Context) error {
+	return db.Pool.Ping(ctx)
+}